@@ -3,9 +3,12 @@ module rss-generator
 go 1.23.7
 
 require (
+	github.com/andybalholm/brotli v1.1.1
+	github.com/andybalholm/cascadia v1.3.3
 	github.com/chromedp/chromedp v0.13.3
 	github.com/robfig/cron/v3 v3.0.1
 	github.com/stretchr/testify v1.10.0
+	golang.org/x/net v0.38.0
 )
 
 require (
@@ -17,6 +20,7 @@ require (
 	github.com/gobwas/pool v0.2.1 // indirect
 	github.com/gobwas/ws v1.4.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	golang.org/x/sys v0.29.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	golang.org/x/sys v0.31.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -5,127 +5,131 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"rss-generator/providers"
+	browserService "rss-generator/services/browser"
 	cacheService "rss-generator/services/cache"
 	cronService "rss-generator/services/cron"
-	"strings"
+	notifyService "rss-generator/services/notify"
+	serverService "rss-generator/services/server"
+	"strconv"
 	"sync"
-
-	"github.com/chromedp/chromedp"
+	"time"
 )
 
 func main() {
-	ctx, cancel := chromedp.NewContext(context.Background())
-	defer cancel()
-	cache := cacheService.NewMemoryCache()
+	// CACHE_DIR keeps the cache on disk so it survives restarts
+	var cache cacheService.Cacher = cacheService.NewMemoryCache()
+	if dir := os.Getenv("CACHE_DIR"); dir != "" {
+		diskCache, err := cacheService.NewDiskCache(dir)
+		if err != nil {
+			log.Fatalf("Failed to open the cache in %s: %v", dir, err)
+		}
+		cache = diskCache
+	} else {
+		log.Println("CACHE_DIR is not set: the run history of the providers, their item history and feeds are kept in memory and lost on restart.")
+	}
 
-	// start the cron service
-	vergeScraper := providers.NewTheVergeScraper(cache)
-	freeCodeCampScraper := providers.NewFreeCodeCampScraper(cache)
-	awsScraper := providers.NewAWSSraper(cache)
-	cssTricksScraper := providers.NewCSSTricksScraper(cache)
-	nodeWeeklyScraper := providers.NewNodeWeeklyScraper(cache)
+	// Scrapes run in the tabs of a shared headless browser
+	browser := browserService.NewPool(browserService.Options{
+		Size:           envInt("BROWSER_POOL_SIZE", browserService.DefaultOptions.Size),
+		MaxUses:        envInt("BROWSER_MAX_USES", browserService.DefaultOptions.MaxUses),
+		WaitTimeout:    envDuration("BROWSER_WAIT_TIMEOUT", browserService.DefaultOptions.WaitTimeout),
+		HealthInterval: envDuration("BROWSER_HEALTH_INTERVAL", browserService.DefaultOptions.HealthInterval),
+	})
+	defer browser.Close()
 
-	cronService := cronService.NewCronService(vergeScraper)
-	err := cronService.AddTheVergeJob()
-	if err != nil {
-		log.Fatalf("Failed to add The Verge job: %v", err)
+	// Failing providers are logged, and posted to ALERT_WEBHOOK_URL when set
+	notifier := notifyService.Multi{notifyService.LogNotifier{}}
+	if url := os.Getenv("ALERT_WEBHOOK_URL"); url != "" {
+		notifier = append(notifier, &notifyService.WebhookNotifier{URL: url})
 	}
-	cronService.SetScraper(freeCodeCampScraper)
-	err = cronService.AddFreeCodeCampJob()
-	if err != nil {
-		log.Fatalf("Failed to add FreeCodeCamp job: %v", err)
+
+	// Load the provider definitions, PROVIDERS_DIR can add or override them
+	registry := providers.NewRegistry(cache, browser)
+	registry.SetNotifier(notifier)
+	if err := registry.LoadBuiltin(); err != nil {
+		log.Fatalf("Failed to load builtin providers: %v", err)
 	}
-	cronService.SetScraper(awsScraper)
-	err = cronService.AddAWSJob()
-	if err != nil {
-		log.Fatalf("Failed to add AWS job: %v", err)
+	if dir := os.Getenv("PROVIDERS_DIR"); dir != "" {
+		if err := registry.LoadDir(dir); err != nil {
+			log.Fatalf("Failed to load providers from %s: %v", dir, err)
+		}
 	}
-	cronService.SetScraper(cssTricksScraper)
-	err = cronService.AddCSSTricksJob()
-	if err != nil {
-		log.Fatalf("Failed to add CSS-Tricks job: %v", err)
+
+	// start the cron service, CRON_SCHEDULE, CRON_TIMEOUT and CRON_JITTER set
+	// the defaults of the providers without their own schedule
+	defaults := cronService.DefaultSchedule
+	if spec := os.Getenv("CRON_SCHEDULE"); spec != "" {
+		defaults.Cron = spec
 	}
-	cronService.SetScraper(nodeWeeklyScraper)
-	err = cronService.AddNodeWeeklyJob()
-	if err != nil {
-		log.Fatalf("Failed to add NodeWeekly job: %v", err)
+	defaults.Timeout = providers.Duration(envDuration("CRON_TIMEOUT", time.Duration(defaults.Timeout)))
+	defaults.Jitter = providers.Duration(envDuration("CRON_JITTER", time.Duration(defaults.Jitter)))
+	cronService := cronService.NewCronService(defaults)
+	if err := cronService.AddProviders(registry); err != nil {
+		log.Fatalf("Failed to add provider jobs: %v", err)
 	}
 	cronService.Start()
 
-	// Run the job when server up
-	scrapers := []struct {
-		name    string
-		scraper providers.Scraper
-	}{
-		{name: "The Verge", scraper: vergeScraper},
-		{name: "FreeCodeCamp", scraper: freeCodeCampScraper},
-		{name: "AWS-Blog", scraper: awsScraper},
-		{name: "CSS-Tricks", scraper: cssTricksScraper},
-		{name: "NodeWeekly", scraper: nodeWeeklyScraper},
-	}
-
 	// Run all scrapers asynchronously on startup
 	var wg sync.WaitGroup
-	for _, s := range scrapers {
+	for _, def := range registry.Definitions() {
+		scraper := registry.MustScraper(def.ID)
 		wg.Add(1)
-		go func(s struct {
-			name    string
-			scraper providers.Scraper
-		}) {
+		go func(name string, scraper providers.Scraper) {
 			defer wg.Done()
-			// Create a new context for each goroutine
-			ctx, cancel := chromedp.NewContext(context.Background())
-			defer cancel()
-			log.Printf("Running %s job immediately on startup...", s.name)
-			if _, err := s.scraper.Scrape(ctx); err != nil {
-				log.Printf("Error running %s job on startup: %v", s.name, err)
+			log.Printf("Running %s job immediately on startup...", name)
+			if _, err := scraper.Scrape(providers.WithTrigger(context.Background(), providers.TriggerStartup)); err != nil {
+				log.Printf("Error running %s job on startup: %v", name, err)
 			} else {
-				log.Printf("%s job completed successfully on startup.", s.name)
+				log.Printf("%s job completed successfully on startup.", name)
 			}
-		}(s)
+		}(def.Title, scraper)
 	}
 	wg.Wait()
 
-	// Define a map of provider names to scraper factories
-	scraperFactories := map[string]func(cacheService.Cacher) providers.Scraper{
-		"theverge":     func(cache cacheService.Cacher) providers.Scraper { return providers.NewTheVergeScraper(cache) },
-		"freecodecamp": func(cache cacheService.Cacher) providers.Scraper { return providers.NewFreeCodeCampScraper(cache) },
-		"aws":          func(cache cacheService.Cacher) providers.Scraper { return providers.NewAWSSraper(cache) },
-		"csstricks":    func(cache cacheService.Cacher) providers.Scraper { return providers.NewCSSTricksScraper(cache) },
-		"nodeweekly":   func(cache cacheService.Cacher) providers.Scraper { return providers.NewNodeWeeklyScraper(cache) },
-	}
-
-	http.HandleFunc("/feed/", func(w http.ResponseWriter, r *http.Request) {
-		// Extract the provider name from the URL path
-		parts := strings.Split(r.URL.Path, "/")
-		if len(parts) >= 4 && parts[len(parts)-1] == "rss.xml" {
-			providerName := parts[len(parts)-2]
-
-			// Check if the provider is supported
-			factory, ok := scraperFactories[providerName]
-			if !ok {
-				http.NotFound(w, r)
-				return
-			}
+	fmt.Println("Running server at http://localhost:8080")
+	server := serverService.NewServer(registry, cronService)
+	server.AdminToken = os.Getenv("ADMIN_TOKEN")
+	server.StaleNotice = envBool("STALE_NOTICE", false)
+	log.Fatal(http.ListenAndServe(":8080", server))
+}
 
-			// Create the scraper for the provider
-			scraper := factory(cache)
-			xmlStr, err := scraper.Scrape(ctx)
-			if err != nil {
-				log.Printf("Error scraping %s: %v", providerName, err)
-				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-				return
-			}
+// envInt reads an integer environment variable.
+func envInt(name string, fallback int) int {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", name, err)
+	}
+	return value
+}
 
-			// Write the RSS XML to the response
-			w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
-			w.Write([]byte(xmlStr))
-			return
-		}
-		http.NotFound(w, r)
-	})
+// envDuration reads a duration environment variable such as "30s".
+func envDuration(name string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback
+	}
+	value, err := time.ParseDuration(raw)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", name, err)
+	}
+	return value
+}
 
-	fmt.Println("Running server at http://localhost:8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+// envBool reads a boolean environment variable such as "true".
+func envBool(name string, fallback bool) bool {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", name, err)
+	}
+	return value
 }
@@ -2,8 +2,10 @@ package providers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	feedService "rss-generator/services/feed"
 	"testing"
 	"time"
 
@@ -11,36 +13,44 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+const cacheKeyAWS = "rss-aws"
+
 func TestNewAWSSraper(t *testing.T) {
 	cache := NewMockCache()
-	scraper := NewAWSSraper(cache)
+	scraper := newTestScraper(t, "aws", cache)
 	assert.NotNil(t, scraper)
 	assert.Equal(t, cache, scraper.Cache)
+	assert.Equal(t, "en-US,en;q=0.9", scraper.Definition.Headers["Accept-Language"])
 }
 
 func TestAWSSraper_Scrape_Cached(t *testing.T) {
 	mockCache := NewMockCache()
-	mockCache.data[cacheKeyAWS] = "cached-xml-data"
-	scraper := NewAWSSraper(mockCache)
+	mockCache.data[cacheKeyAWS] = `{"title":"cached","items":[{"title":"Cached Article","guid":"cached-guid"}]}`
+	scraper := newTestScraper(t, "aws", mockCache)
 
 	ctx, cancel := chromedp.NewContext(context.Background())
 	defer cancel()
 
 	result, err := scraper.Scrape(ctx)
 	assert.NoError(t, err)
-	assert.Equal(t, "cached-xml-data", result)
+	assert.Equal(t, "cached", result.Title)
+	assert.Equal(t, "Cached Article", result.Items[0].Title)
 }
 
 func TestAWSSraper_Scrape_NotCached(t *testing.T) {
 	mockCache := NewMockCache()
-	scraper := NewAWSSraper(mockCache)
+	scraper := newTestScraper(t, "aws", mockCache)
 
 	ctx, cancel := chromedp.NewContext(context.Background())
 	defer cancel()
 
-	result, err := scraper.Scrape(ctx)
+	feed, err := scraper.Scrape(ctx)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotEmpty(t, feed.Items)
+	result, err := feedService.Build(feedService.FormatRSS, feed, feedService.Options{})
 	assert.NoError(t, err)
-	assert.NotEmpty(t, result)
 	assert.Contains(t, result, "<rss")
 	assert.Contains(t, result, "<channel")
 	assert.Contains(t, result, "<item")
@@ -51,12 +61,13 @@ func TestAWSSraper_Scrape_NotCached(t *testing.T) {
 	assert.Contains(t, result, "<description>")
 	assert.Contains(t, result, "<pubDate>")
 	assert.Contains(t, result, "<guid>")
-	assert.Equal(t, result, mockCache.data[cacheKeyAWS])
+	cached, _ := json.Marshal(feed)
+	assert.Equal(t, string(cached), mockCache.data[cacheKeyAWS])
 }
 
 func TestAWSSraper_Scrape_Error(t *testing.T) {
 	mockCache := NewMockCache()
-	scraper := NewAWSSraper(mockCache)
+	scraper := newTestScraper(t, "aws", mockCache)
 
 	// Create a context with a very short timeout to force an error
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Millisecond)
@@ -64,40 +75,45 @@ func TestAWSSraper_Scrape_Error(t *testing.T) {
 
 	result, err := scraper.Scrape(ctx)
 	assert.Error(t, err)
-	assert.Empty(t, result)
+	assert.Nil(t, result)
 }
 
 func TestAWSSraper_Scrape_SetCacheError(t *testing.T) {
 	mockCache := NewMockCache()
 	mockCache.err = errors.New("set cache error")
-	scraper := NewAWSSraper(mockCache)
+	scraper := newTestScraper(t, "aws", mockCache)
 
 	ctx, cancel := chromedp.NewContext(context.Background())
 	defer cancel()
 
 	result, err := scraper.Scrape(ctx)
-	assert.NoError(t, err)
-	assert.NotEmpty(t, result)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotEmpty(t, result.Items)
 	assert.NotContains(t, mockCache.data, cacheKeyAWS)
 }
 
 func TestGeneratedAWSFeed(t *testing.T) {
-	articles := []AWSArticle{
+	def := *newTestScraper(t, "aws", NewMockCache()).Definition
+	def.Title, def.Link, def.Description = "Test Feed", "https://www.example.com", "Test Description"
+	items := []siteItem{
 		{
-			Title:       "Test Article 1",
-			Link:        "https://www.example.com/article1",
-			Description: "Summary 1",
-			Date:        "October 27, 2023",
+			"title":   {"Test Article 1"},
+			"link":    {"https://www.example.com/article1"},
+			"summary": {"Summary 1"},
+			"date":    {"October 27, 2023"},
 		},
 		{
-			Title:       "Test Article 2",
-			Link:        "https://www.example.com/article2",
-			Description: "Summary 2",
-			Date:        "Jan 2, 2023",
+			"title":   {"Test Article 2"},
+			"link":    {"https://www.example.com/article2"},
+			"summary": {"Summary 2"},
+			"date":    {"Jan 2, 2023"},
 		},
 	}
 
-	xmlStr := generatedAWSFeed("Test Feed", "https://www.example.com", "Test Description", articles)
+	xmlStr, err := feedService.Build(feedService.FormatRSS, def.feed(items), feedService.Options{})
+	assert.NoError(t, err)
 	fmt.Println(xmlStr)
 	assert.NotEmpty(t, xmlStr)
 	assert.Contains(t, xmlStr, "<rss")
@@ -112,4 +128,6 @@ func TestGeneratedAWSFeed(t *testing.T) {
 	assert.Contains(t, xmlStr, "<title>Test Article 2</title>")
 	assert.Contains(t, xmlStr, "<link>https://www.example.com/article2</link>")
 	assert.Contains(t, xmlStr, "<description>Summary 2</description>")
+	assert.Contains(t, xmlStr, "<pubDate>Fri, 27 Oct 2023 00:00:00 +0000</pubDate>")
+	assert.Contains(t, xmlStr, "<pubDate>Mon, 02 Jan 2023 00:00:00 +0000</pubDate>")
 }
@@ -0,0 +1,97 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MergeID is the id of the ad hoc merged feed, /feed/merge/rss.xml?providers=a,b.
+// It cannot be used by a provider or a bundle.
+const MergeID = "merge"
+
+// Bundle is a named feed combining the items of several providers. Bundles
+// are defined in a bundles.yaml, or bundles.json, file next to the provider
+// definitions.
+type Bundle struct {
+	ID          string   `yaml:"id" json:"id"`
+	Title       string   `yaml:"title" json:"title"`
+	Description string   `yaml:"description" json:"description"`
+	Providers   []string `yaml:"providers" json:"providers"` // ids of the merged providers
+}
+
+// isBundleFile reports whether name is a bundles file rather than a
+// provider definition.
+func isBundleFile(name string) bool {
+	ext := path.Ext(name)
+	switch strings.ToLower(ext) {
+	case ".json", ".yaml", ".yml":
+		return strings.EqualFold(strings.TrimSuffix(name, ext), "bundles")
+	}
+	return false
+}
+
+// ParseBundles decodes a bundles file, using the file extension of name to
+// pick between YAML and JSON.
+func ParseBundles(name string, data []byte) ([]*Bundle, error) {
+	var bundles []*Bundle
+	var err error
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json":
+		err = json.Unmarshal(data, &bundles)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &bundles)
+	default:
+		return nil, fmt.Errorf("unsupported bundles file %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", name, err)
+	}
+	return bundles, nil
+}
+
+// loadBundles reads the bundles files in the root of fsys.
+func loadBundles(fsys fs.FS) ([]*Bundle, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	var bundles []*Bundle
+	for _, entry := range entries {
+		if entry.IsDir() || !isBundleFile(entry.Name()) {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := ParseBundles(entry.Name(), data)
+		if err != nil {
+			return nil, err
+		}
+		bundles = append(bundles, parsed...)
+	}
+	return bundles, nil
+}
+
+// Validate checks the bundle on its own, the providers are checked when it
+// is registered.
+func (b *Bundle) Validate() error {
+	if b.ID == "" {
+		return fmt.Errorf("bundle is missing an id")
+	}
+	if b.ID == MergeID {
+		return fmt.Errorf("bundle id %q is reserved", b.ID)
+	}
+	if len(b.Providers) == 0 {
+		return fmt.Errorf("bundle %q has no providers", b.ID)
+	}
+	if b.Title == "" {
+		b.Title = b.ID
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package providers
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testDefinition = `
+id: %s
+url: https://www.example.com/
+items: .post
+fields:
+  title:
+    selector: a
+  link:
+    selector: a
+    attr: href
+`
+
+func TestRegistry_LoadFS_Bundles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.yaml": {Data: []byte(fmtDefinition("a"))},
+		"b.yaml": {Data: []byte(fmtDefinition("b"))},
+		"bundles.yaml": {Data: []byte(`
+- id: webdev
+  title: Web development
+  providers: [a, b]
+`)},
+	}
+	registry := NewRegistry(NewMockCache(), nil)
+	if !assert.NoError(t, registry.LoadFS(fsys)) {
+		return
+	}
+	assert.Len(t, registry.Definitions(), 2, "the bundles file is not a definition")
+	bundle, ok := registry.Bundle("webdev")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"a", "b"}, bundle.Providers)
+	assert.Len(t, registry.Bundles(), 1)
+}
+
+func TestRegistry_RegisterBundle(t *testing.T) {
+	registry := NewRegistry(NewMockCache(), nil)
+	def, err := ParseDefinition("a.yaml", []byte(fmtDefinition("a")))
+	assert.NoError(t, err)
+	assert.NoError(t, registry.Register(def))
+
+	assert.ErrorContains(t, registry.RegisterBundle(&Bundle{ID: "all", Providers: []string{"a", "missing"}}), "unknown provider")
+	assert.ErrorContains(t, registry.RegisterBundle(&Bundle{ID: "a", Providers: []string{"a"}}), "id of a provider")
+	assert.ErrorContains(t, registry.RegisterBundle(&Bundle{ID: MergeID, Providers: []string{"a"}}), "reserved")
+	assert.Error(t, registry.RegisterBundle(&Bundle{ID: "empty"}))
+
+	bundle := &Bundle{ID: "all", Providers: []string{"a"}}
+	assert.NoError(t, registry.RegisterBundle(bundle))
+	assert.Equal(t, "all", bundle.Title)
+
+	_, err = ParseDefinition("merge.yaml", []byte(fmtDefinition(MergeID)))
+	assert.ErrorContains(t, err, "reserved")
+}
+
+func fmtDefinition(id string) string {
+	return fmt.Sprintf(testDefinition, id)
+}
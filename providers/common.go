@@ -1,7 +1,10 @@
 package providers
 
-import "context"
+import (
+	"context"
+	feedService "rss-generator/services/feed"
+)
 
 type Scraper interface {
-	Scrape(ctx context.Context, isJob ...string) (string, error)
+	Scrape(ctx context.Context, isJob ...string) (*feedService.Feed, error)
 }
@@ -0,0 +1,78 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"log"
+	articleService "rss-generator/services/article"
+	feedService "rss-generator/services/feed"
+	"sync"
+	"time"
+)
+
+const (
+	defaultContentLimit = 20                  // articles fetched per scrape
+	contentWorkers      = 4                   // articles fetched concurrently
+	contentTTL          = 30 * 24 * time.Hour // long after the articles left the feed
+)
+
+// contentCacheKey is the cache key of the body of an article. GUIDs are
+// hashed as they are often long URLs.
+func contentCacheKey(id, guid string) string {
+	sum := sha1.Sum([]byte(guid))
+	return "content-" + id + "-" + hex.EncodeToString(sum[:])
+}
+
+// fillContent sets the full article body of the items. Bodies are cached by
+// GUID, so each article is only fetched once. The articles that cannot be
+// fetched keep their content and are tried again on the next scrape.
+func (s *SiteScraper) fillContent(ctx context.Context, feed *feedService.Feed) {
+	def := s.Definition
+	limit := def.Content.Limit
+	if limit == 0 {
+		limit = defaultContentLimit
+	}
+
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, contentWorkers)
+	fetched, cached := 0, 0
+	for i := range feed.Items {
+		article := &feed.Items[i]
+		key := contentCacheKey(def.ID, article.GUID)
+		if content, ok := s.Cache.Get(key); ok {
+			article.Content = content
+			cached++
+			continue
+		}
+		if fetched >= limit {
+			continue
+		}
+		fetched++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			workers <- struct{}{}
+			defer func() { <-workers }()
+			content, err := s.fetchContent(ctx, article.Link)
+			if err != nil {
+				log.Printf("Error extracting the content of %s: %v", article.Link, err)
+				return
+			}
+			article.Content = content
+			s.Cache.Set(key, content, contentTTL)
+		}()
+	}
+	wg.Wait()
+	log.Printf("%s articles: %d fetched, %d from the cache.", def.Title, fetched, cached)
+}
+
+// fetchContent downloads an article and extracts its body.
+func (s *SiteScraper) fetchContent(ctx context.Context, link string) (string, error) {
+	data, err := download(ctx, nil, link, s.Definition.Headers, htmlAccept)
+	if err != nil {
+		return "", err
+	}
+	return articleService.Extract(bytes.NewReader(data), link, s.Definition.Content.Selector)
+}
@@ -0,0 +1,63 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSiteScraper_Scrape_Content(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path == "/article-2" {
+			http.Error(w, "gone", http.StatusGone)
+			return
+		}
+		fmt.Fprintf(w, `<html><body><nav><a href="/">Home</a></nav><article>
+			<p>The body of %s is long enough to be picked, with commas, and more words.</p>
+			<p>It goes on in <a href="/related">a second paragraph</a>, followed by a script.</p>
+			<script>alert(1)</script>
+		</article></body></html>`, r.URL.Path)
+	}))
+	defer server.Close()
+
+	cache := NewMockCache()
+	scraper := testSiteScraper(cache)
+	scraper.Definition.URL = server.URL + "/"
+	scraper.Definition.Content = Content{Extract: true}
+	var calls atomic.Int32
+	scraper.Fetcher = FetcherFunc(func(ctx context.Context, def *Definition) ([]map[string][]string, error) {
+		calls.Add(1)
+		return []map[string][]string{
+			{"title": {"Article 1"}, "link": {"/article-1"}},
+			{"title": {"Article 2"}, "link": {"/article-2"}},
+		}, nil
+	})
+
+	feed, err := scraper.Scrape(context.Background(), "true")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, feed.Items[0].Content, "The body of /article-1 is long enough")
+	assert.Contains(t, feed.Items[0].Content, `<a href="`+server.URL+`/related">`)
+	assert.NotContains(t, feed.Items[0].Content, "<script")
+	assert.NotContains(t, feed.Items[0].Content, "Home")
+	assert.Empty(t, feed.Items[1].Content, "failed extractions are not fatal")
+	assert.Equal(t, int32(2), requests.Load())
+
+	// Extracted bodies are cached by GUID, failures are tried again
+	feed, err = scraper.Scrape(context.Background(), "true")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, feed.Items[0].Content, "The body of /article-1")
+	assert.Equal(t, int32(3), requests.Load())
+	_, ok := cache.Get(contentCacheKey("example", server.URL+"/article-1"))
+	assert.True(t, ok)
+}
@@ -0,0 +1,258 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Definition describes a site that can be scraped without writing Go code.
+// Definitions are loaded from YAML or JSON files, see providers/definitions.
+type Definition struct {
+	ID           string            `yaml:"id" json:"id"`
+	Title        string            `yaml:"title" json:"title"`
+	Link         string            `yaml:"link" json:"link"`
+	Description  string            `yaml:"description" json:"description"`
+	URL          string            `yaml:"url" json:"url"`                   // page to scrape, defaults to Link
+	Fetch        string            `yaml:"fetch" json:"fetch"`               // FetchBrowser, FetchHTTP, FetchAuto or FetchFeed
+	Ready        string            `yaml:"ready" json:"ready"`               // selector to wait for before extracting
+	Items        string            `yaml:"items" json:"items"`               // selector matching one element per article
+	Fields       map[string]Field  `yaml:"fields" json:"fields"`             // keyed by item field, e.g. title, link, date
+	DateFormats  []string          `yaml:"dateFormats" json:"dateFormats"`   // Go layouts tried before the common ones
+	Timezone     string            `yaml:"timezone" json:"timezone"`         // IANA zone of dates without an offset, defaults to UTC
+	DateFallback string            `yaml:"dateFallback" json:"dateFallback"` // DateFallbackNone or DateFallbackNow for missing or unparsable dates
+	Headers      map[string]string `yaml:"headers" json:"headers"`           // extra request headers
+	Schedule     Schedule          `yaml:"schedule" json:"schedule"`         // when the cron refreshes the feed
+	TTL          Duration          `yaml:"ttl" json:"ttl"`                   // how long a scraped feed is fresh, zero means forever
+	Content      Content           `yaml:"content" json:"content"`           // extraction of the full articles
+	History      History           `yaml:"history" json:"history"`           // items kept across scrapes
+	Expect       Expect            `yaml:"expect" json:"expect"`             // what a healthy scrape returns
+}
+
+// Expect describes a healthy scrape, so a site redesign is noticed instead
+// of overwriting the feed with an empty one. Zero values use the defaults.
+type Expect struct {
+	MinItems     int      `yaml:"minItems" json:"minItems"`         // items found on the page, defaults to 1
+	Required     []string `yaml:"required" json:"required"`         // fields every item must have besides title and link, e.g. date
+	Dates        bool     `yaml:"dates" json:"dates"`               // the dates found must be parseable
+	ReadyTimeout Duration `yaml:"readyTimeout" json:"readyTimeout"` // how long the ready selector may take to appear, defaults to 1m
+}
+
+// History configures the items kept across scrapes, so an article that left
+// the page is still served. Zero values use the defaults.
+type History struct {
+	Items    int      `yaml:"items" json:"items"`       // items served, defaults to 50
+	MaxItems int      `yaml:"maxItems" json:"maxItems"` // items retained, defaults to 500
+	MaxAge   Duration `yaml:"maxAge" json:"maxAge"`     // how long an item is retained after it was first seen, defaults to 90 days
+}
+
+// Content configures the extraction of the full article of every item,
+// emitted as content:encoded in RSS and content in Atom.
+type Content struct {
+	Extract  bool   `yaml:"extract" json:"extract"`   // follow the item links and extract the article body
+	Selector string `yaml:"selector" json:"selector"` // element holding the body, found by a readability algorithm when empty
+	Limit    int    `yaml:"limit" json:"limit"`       // articles fetched per scrape, defaults to 20
+}
+
+// Schedule configures the background refresh of a provider. Empty values
+// fall back to the defaults of the cron service.
+type Schedule struct {
+	Cron    string   `yaml:"cron" json:"cron"`       // cron expression with seconds, or a descriptor such as @hourly
+	Timeout Duration `yaml:"timeout" json:"timeout"` // maximum duration of a run
+	Jitter  Duration `yaml:"jitter" json:"jitter"`   // random delay added before each run
+}
+
+// Duration is a time.Duration written as "10m" in definition files.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	value, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(value)
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// defaultTimeout bounds the scrapes of the providers without a timeout.
+const defaultTimeout = 10 * time.Minute
+
+const (
+	FetchBrowser = "browser" // render the page in the headless browser, the default
+	FetchHTTP    = "http"    // download the page and parse the HTML, no JavaScript
+	FetchAuto    = "auto"    // try FetchHTTP and fall back to FetchBrowser
+	FetchFeed    = "feed"    // url is an RSS, Atom or JSON feed passed through
+)
+
+// feedFields are the fields filled by the feed fetcher. A definition of a
+// feed may still configure them, e.g. to strip a suffix from the titles.
+var feedFields = map[string]Field{
+	"title":     {},
+	"link":      {},
+	"summary":   {},
+	"content":   {},
+	"author":    {Multiple: true},
+	"category":  {Multiple: true},
+	"guid":      {},
+	"image":     {},
+	"enclosure": {Multiple: true},
+	"date":      {},
+	"updated":   {},
+}
+
+const (
+	DateFallbackNone = "none" // leave the item undated, the default
+	DateFallbackNow  = "now"  // use the scrape time
+)
+
+// Field describes how to extract a single value from an item element.
+type Field struct {
+	Selector string `yaml:"selector" json:"selector"` // relative to the item, empty means the item itself
+	Attr     string `yaml:"attr" json:"attr"`         // attribute to read instead of the text
+	Sibling  bool   `yaml:"sibling" json:"sibling"`   // read the text node right after the match
+	Multiple bool   `yaml:"multiple" json:"multiple"` // collect every match instead of the first one
+	Split    string `yaml:"split" json:"split"`       // split the value and keep the part at Index
+	Index    int    `yaml:"index" json:"index"`
+	Strip    string `yaml:"strip" json:"strip"` // remove this string from the value
+}
+
+// Validate checks that the definition has everything the engine needs.
+func (d *Definition) Validate() error {
+	if d.ID == "" {
+		return fmt.Errorf("definition is missing an id")
+	}
+	if d.ID == MergeID {
+		return fmt.Errorf("definition id %q is reserved", d.ID)
+	}
+	if d.URL == "" {
+		d.URL = d.Link
+	}
+	if d.Link == "" {
+		d.Link = d.URL
+	}
+	if d.URL == "" {
+		return fmt.Errorf("definition %q is missing a url", d.ID)
+	}
+	if d.Fetch == FetchFeed {
+		if d.Fields == nil {
+			d.Fields = make(map[string]Field, len(feedFields))
+		}
+		for name, field := range feedFields {
+			if _, ok := d.Fields[name]; !ok {
+				d.Fields[name] = field
+			}
+		}
+	} else if d.Items == "" {
+		return fmt.Errorf("definition %q is missing an items selector", d.ID)
+	}
+	for _, name := range []string{"title", "link"} {
+		if _, ok := d.Fields[name]; !ok {
+			return fmt.Errorf("definition %q is missing the %s field", d.ID, name)
+		}
+	}
+	if d.Title == "" {
+		d.Title = d.ID
+	}
+	switch d.Fetch {
+	case "", FetchBrowser, FetchHTTP, FetchAuto, FetchFeed:
+	default:
+		return fmt.Errorf("definition %q has an unknown fetch mode %q", d.ID, d.Fetch)
+	}
+	switch d.DateFallback {
+	case "", DateFallbackNone, DateFallbackNow:
+	default:
+		return fmt.Errorf("definition %q has an unknown dateFallback %q", d.ID, d.DateFallback)
+	}
+	if d.Content.Limit < 0 {
+		return fmt.Errorf("definition %q has a negative content limit", d.ID)
+	}
+	if d.History.Items < 0 || d.History.MaxItems < 0 || d.History.MaxAge < 0 {
+		return fmt.Errorf("definition %q has a negative history limit", d.ID)
+	}
+	if d.Expect.MinItems < 0 || d.Expect.ReadyTimeout < 0 {
+		return fmt.Errorf("definition %q has a negative expectation", d.ID)
+	}
+	for _, name := range d.Expect.Required {
+		if _, ok := d.Fields[name]; !ok {
+			return fmt.Errorf("definition %q expects the %s field, which it does not extract", d.ID, name)
+		}
+	}
+	if d.Schedule.Timeout < 0 || d.Schedule.Jitter < 0 || d.TTL < 0 {
+		return fmt.Errorf("definition %q has a negative schedule duration", d.ID)
+	}
+	if d.Timezone != "" {
+		if _, err := time.LoadLocation(d.Timezone); err != nil {
+			return fmt.Errorf("definition %q has an invalid timezone: %w", d.ID, err)
+		}
+	}
+	return nil
+}
+
+// timeout returns the maximum duration of a scrape of the provider.
+func (d *Definition) timeout() time.Duration {
+	if d.Schedule.Timeout > 0 {
+		return time.Duration(d.Schedule.Timeout)
+	}
+	return defaultTimeout
+}
+
+// ParseDefinition decodes a definition, using the file extension of name to
+// pick between YAML and JSON.
+func ParseDefinition(name string, data []byte) (*Definition, error) {
+	var def Definition
+	var err error
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json":
+		err = json.Unmarshal(data, &def)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &def)
+	default:
+		return nil, fmt.Errorf("unsupported definition file %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", name, err)
+	}
+	if err := def.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid definition %s: %w", name, err)
+	}
+	return &def, nil
+}
+
+// LoadDefinitions reads every YAML and JSON definition in the root of fsys,
+// except the bundles files.
+func LoadDefinitions(fsys fs.FS) ([]*Definition, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	var defs []*Definition
+	for _, entry := range entries {
+		if entry.IsDir() || isBundleFile(entry.Name()) {
+			continue
+		}
+		switch strings.ToLower(path.Ext(entry.Name())) {
+		case ".json", ".yaml", ".yml":
+		default:
+			continue
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		def, err := ParseDefinition(entry.Name(), data)
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
@@ -0,0 +1,76 @@
+package providers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMinItems     = 1
+	defaultReadyTimeout = time.Minute
+)
+
+// ValidationError reports a scrape that does not meet the expectations of
+// its provider. The previous feed is kept.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "unexpected scrape result: " + strings.Join(e.Problems, "; ")
+}
+
+func (e Expect) minItems() int {
+	if e.MinItems == 0 {
+		return defaultMinItems
+	}
+	return e.MinItems
+}
+
+func (e Expect) readyTimeout() time.Duration {
+	if e.ReadyTimeout == 0 {
+		return defaultReadyTimeout
+	}
+	return time.Duration(e.ReadyTimeout)
+}
+
+// validate checks the normalized items of a scrape against the
+// expectations of the definition.
+func (d *Definition) validate(items []siteItem) error {
+	var problems []string
+	if min := d.Expect.minItems(); len(items) < min {
+		problems = append(problems, fmt.Sprintf("%d items found, expected at least %d", len(items), min))
+	}
+	for _, name := range d.Expect.Required {
+		missing := 0
+		for _, item := range items {
+			if item.first(name) == "" {
+				missing++
+			}
+		}
+		if missing > 0 {
+			problems = append(problems, fmt.Sprintf("%d of %d items have no %s", missing, len(items), name))
+		}
+	}
+	if d.Expect.Dates {
+		for _, name := range []string{"date", "updated"} {
+			invalid, example := 0, ""
+			for _, item := range items {
+				if raw := item.first(name); raw != "" {
+					if _, err := d.parseItemDate(raw); err != nil {
+						invalid++
+						example = raw
+					}
+				}
+			}
+			if invalid > 0 {
+				problems = append(problems, fmt.Sprintf("%d of %d items have an unparsable %s such as %q", invalid, len(items), name, example))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
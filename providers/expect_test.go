@@ -0,0 +1,118 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	browserService "rss-generator/services/browser"
+	notifyService "rss-generator/services/notify"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefinitionValidate_Expect(t *testing.T) {
+	def := testSiteScraper(NewMockCache()).Definition
+	def.Expect = Expect{Required: []string{"date"}}
+	assert.EqualError(t, def.Validate(), `definition "example" expects the date field, which it does not extract`)
+
+	def.Expect = Expect{MinItems: -1}
+	assert.EqualError(t, def.Validate(), `definition "example" has a negative expectation`)
+}
+
+func TestDefinitionValidate_Items(t *testing.T) {
+	def := &Definition{ID: "example", Expect: Expect{MinItems: 2, Required: []string{"date"}, Dates: true}}
+	items := []siteItem{
+		{"title": {"First"}, "date": {"2024-01-31"}},
+		{"title": {"Second"}, "date": {"yesterday-ish"}},
+		{"title": {"Third"}},
+	}
+	assert.NoError(t, (&Definition{}).validate(items[:1]), "one item is enough by default")
+	assert.EqualError(t, (&Definition{}).validate(nil), "unexpected scrape result: 0 items found, expected at least 1")
+
+	err := def.validate(items)
+	var validation *ValidationError
+	if assert.ErrorAs(t, err, &validation) {
+		assert.Equal(t, []string{
+			"1 of 3 items have no date",
+			`1 of 3 items have an unparsable date such as "yesterday-ish"`,
+		}, validation.Problems)
+	}
+}
+
+func TestSiteScraper_Scrape_Validation(t *testing.T) {
+	cache := NewMockCache()
+	scraper := testSiteScraper(cache)
+	alerts := make(chan notifyService.Alert, 4)
+	scraper.Notifier = notifyService.NotifierFunc(func(ctx context.Context, alert notifyService.Alert) error {
+		alerts <- alert
+		return nil
+	})
+	var calls atomic.Int32
+	scraper.Fetcher = fakeFetch(&calls, nil)
+	good, err := scraper.Scrape(context.Background())
+	assert.NoError(t, err)
+
+	// The redesigned page has no items anymore
+	scraper.Fetcher = FetcherFunc(func(ctx context.Context, def *Definition) ([]map[string][]string, error) {
+		return []map[string][]string{{"title": {"Cookie banner"}}}, nil
+	})
+	_, err = scraper.Scrape(context.Background(), "true")
+	assert.EqualError(t, err, "unexpected scrape result: 0 items found, expected at least 1")
+	_, err = scraper.Scrape(context.Background(), "true")
+	assert.Error(t, err)
+
+	feed, err := scraper.Scrape(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, good.Items, feed.Items, "the previous feed is kept")
+	assert.Equal(t, RunFailed, scraper.Runs()[0].Outcome)
+
+	alert := <-alerts
+	assert.Equal(t, notifyService.AlertFailing, alert.Kind)
+	assert.Equal(t, "example", alert.Provider)
+	assert.Contains(t, alert.Error, "0 items found")
+
+	scraper.Fetcher = fakeFetch(&calls, nil)
+	_, err = scraper.Scrape(context.Background(), "true")
+	assert.NoError(t, err)
+	alert = <-alerts
+	assert.Equal(t, notifyService.AlertRecovered, alert.Kind, "the second failure is not reported")
+	select {
+	case alert := <-alerts:
+		t.Errorf("unexpected alert %v", alert)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestSiteScraper_Scrape_PoolExhausted(t *testing.T) {
+	scraper := testSiteScraper(NewMockCache())
+	alerts := make(chan notifyService.Alert, 4)
+	scraper.Notifier = notifyService.NotifierFunc(func(ctx context.Context, alert notifyService.Alert) error {
+		alerts <- alert
+		return nil
+	})
+	scraper.Fetcher = FetcherFunc(func(ctx context.Context, def *Definition) ([]map[string][]string, error) {
+		return nil, fmt.Errorf("%w after 30s", browserService.ErrPoolExhausted)
+	})
+
+	_, err := scraper.Scrape(context.Background(), "true")
+	assert.ErrorIs(t, err, browserService.ErrPoolExhausted)
+	assert.Equal(t, RunFailed, scraper.Runs()[0].Outcome)
+	_, failing := scraper.Failing()
+	assert.False(t, failing, "a busy browser is not a failing provider")
+	select {
+	case alert := <-alerts:
+		t.Errorf("unexpected alert %v", alert)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestBackground(t *testing.T) {
+	for _, trigger := range []string{TriggerCron, TriggerStartup, TriggerStale} {
+		assert.True(t, background(trigger), trigger)
+	}
+	for _, trigger := range []string{TriggerOnDemand, TriggerCacheMiss, ""} {
+		assert.False(t, background(trigger), trigger)
+	}
+}
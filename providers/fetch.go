@@ -0,0 +1,55 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	browserService "rss-generator/services/browser"
+)
+
+// Fetcher loads the page of a definition and returns the raw values of every
+// item, keyed by field name. The values are post processed by normalize.
+type Fetcher interface {
+	Fetch(ctx context.Context, def *Definition) ([]map[string][]string, error)
+}
+
+// FetcherFunc adapts a function to the Fetcher interface.
+type FetcherFunc func(ctx context.Context, def *Definition) ([]map[string][]string, error)
+
+func (f FetcherFunc) Fetch(ctx context.Context, def *Definition) ([]map[string][]string, error) {
+	return f(ctx, def)
+}
+
+// newFetcher returns the fetcher of the fetch mode of the definition.
+func newFetcher(def *Definition, browser *browserService.Pool) Fetcher {
+	switch def.Fetch {
+	case FetchHTTP:
+		return &HTTPFetcher{}
+	case FetchFeed:
+		return &FeedFetcher{}
+	case FetchAuto:
+		return &AutoFetcher{HTTP: &HTTPFetcher{}, Browser: &BrowserFetcher{Pool: browser}}
+	default:
+		return &BrowserFetcher{Pool: browser}
+	}
+}
+
+// AutoFetcher tries the cheap HTTP fetch first and only starts the browser
+// when it fails or finds no item, e.g. when the items are rendered by
+// JavaScript.
+type AutoFetcher struct {
+	HTTP    Fetcher
+	Browser Fetcher
+}
+
+func (f *AutoFetcher) Fetch(ctx context.Context, def *Definition) ([]map[string][]string, error) {
+	raw, err := f.HTTP.Fetch(ctx, def)
+	if err == nil && len(raw) > 0 {
+		return raw, nil
+	}
+	if err == nil {
+		err = fmt.Errorf("no item found")
+	}
+	log.Printf("Fetching %s over HTTP failed, falling back to the browser: %v", def.ID, err)
+	return f.Browser.Fetch(ctx, def)
+}
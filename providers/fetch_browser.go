@@ -0,0 +1,115 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	browserService "rss-generator/services/browser"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// BrowserFetcher loads the page in a headless browser, for the sites that
+// render their content with JavaScript.
+type BrowserFetcher struct {
+	Pool *browserService.Pool // lends the tabs to scrape in, ctx is used as the tab when nil
+}
+
+// Fetch loads the page in a tab of the pool and extracts the items with the
+// script built by extractScript.
+func (f *BrowserFetcher) Fetch(ctx context.Context, def *Definition) ([]map[string][]string, error) {
+	script, err := extractScript(def)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []map[string][]string
+	actions := []chromedp.Action{}
+	if len(def.Headers) > 0 {
+		headers := network.Headers{}
+		for k, v := range def.Headers {
+			headers[k] = v
+		}
+		actions = append(actions, network.SetExtraHTTPHeaders(headers))
+	}
+	actions = append(actions, chromedp.Navigate(def.URL))
+	if def.Ready != "" {
+		// A redesign removing the selector would otherwise block until the
+		// timeout of the whole scrape
+		timeout := def.Expect.readyTimeout()
+		actions = append(actions, chromedp.ActionFunc(func(ctx context.Context) error {
+			readyCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			err := chromedp.WaitReady(def.Ready).Do(readyCtx)
+			if err != nil && readyCtx.Err() != nil && ctx.Err() == nil {
+				return fmt.Errorf("%q not found in the page after %s", def.Ready, timeout)
+			}
+			return err
+		}))
+	}
+	actions = append(actions, chromedp.Evaluate(script, &raw))
+
+	run := func(ctx context.Context) error {
+		return chromedp.Run(ctx, actions...)
+	}
+	if background(triggerFrom(ctx)) {
+		// Scheduled scrapes start together, they queue for a tab until
+		// their own deadline
+		ctx = browserService.WithoutWaitTimeout(ctx)
+	}
+	if f.Pool != nil {
+		// The pool recycles the tab when the navigation fails
+		err = f.Pool.Run(ctx, run)
+	} else {
+		err = run(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// extractScript builds the JavaScript evaluated in the page. It only reads
+// raw strings, the post processing is done by normalize.
+func extractScript(def *Definition) (string, error) {
+	spec, err := json.Marshal(struct {
+		Items  string           `json:"items"`
+		Fields map[string]Field `json:"fields"`
+	}{def.Items, def.Fields})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`
+		(() => {
+			const spec = %s;
+			const read = (el, field) => {
+				if (field.sibling) {
+					const node = el.nextSibling;
+					return node ? node.textContent : '';
+				}
+				if (field.attr) {
+					return el.getAttribute(field.attr) || '';
+				}
+				return el.innerText || el.textContent || '';
+			};
+			const pick = (root, field) => {
+				if (!field.selector) {
+					return [read(root, field)];
+				}
+				if (field.multiple) {
+					return Array.from(root.querySelectorAll(field.selector)).map(el => read(el, field));
+				}
+				const el = root.querySelector(field.selector);
+				return el ? [read(el, field)] : [];
+			};
+			return Array.from(document.querySelectorAll(spec.items)).map(item => {
+				const values = {};
+				for (const [name, field] of Object.entries(spec.fields)) {
+					values[name] = pick(item, field);
+				}
+				return values;
+			});
+		})()
+	`, spec), nil
+}
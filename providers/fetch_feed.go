@@ -0,0 +1,374 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// maxTitleLength bounds the titles made up from the summary of the items
+// published without one.
+const maxTitleLength = 100
+
+// FeedFetcher downloads an upstream RSS, Atom or JSON feed and returns its
+// items as raw values, so they go through the same normalization as the
+// scraped ones: dates are parsed with the layouts of the definition, links
+// are resolved and the items without a link are dropped.
+//
+// The parser is lenient: HTML entities, unknown namespaces and feeds cut
+// short are accepted, keeping the items read so far.
+type FeedFetcher struct {
+	Client *http.Client // http.DefaultClient when nil
+}
+
+func (f *FeedFetcher) Fetch(ctx context.Context, def *Definition) ([]map[string][]string, error) {
+	data, err := download(ctx, f.Client, def.URL, def.Headers, "application/rss+xml, application/atom+xml, application/feed+json, application/json;q=0.9, application/xml;q=0.9, */*;q=0.8")
+	if err != nil {
+		return nil, err
+	}
+	return parseFeed(data)
+}
+
+// parseFeed detects the format of the feed and extracts its items.
+func parseFeed(data []byte) ([]map[string][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		return parseJSONFeed(trimmed)
+	}
+	return parseXMLFeed(data)
+}
+
+// xmlText is an element whose content is text, HTML or, in Atom, XHTML.
+type xmlText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t *xmlText) String() string {
+	if t == nil {
+		return ""
+	}
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.Inner)
+	}
+	return strings.TrimSpace(t.Text)
+}
+
+// markup returns the content with the HTML tags some feeds put unescaped
+// in their titles, which String drops with their text.
+func (t *xmlText) markup() string {
+	if t == nil {
+		return ""
+	}
+	if strings.Contains(t.Inner, "<") && !strings.Contains(t.Inner, "<![CDATA[") {
+		return strings.TrimSpace(t.Inner)
+	}
+	return t.String()
+}
+
+// xmlItem is the union of an RSS item and an Atom entry.
+type xmlItem struct {
+	Title       *xmlText   `xml:"title"`
+	Description *xmlText   `xml:"description"`
+	Summary     *xmlText   `xml:"summary"`
+	Encoded     string     `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Content     *xmlText   `xml:"http://www.w3.org/2005/Atom content"`
+	GUID        string     `xml:"guid"`
+	ID          string     `xml:"id"`
+	PubDate     string     `xml:"pubDate"`
+	Published   string     `xml:"published"`
+	Issued      string     `xml:"issued"`
+	Date        string     `xml:"date"`
+	Updated     string     `xml:"updated"`
+	Modified    string     `xml:"modified"`
+	Creators    []string   `xml:"creator"`
+	Authors     []xmlActor `xml:"author"`
+	Categories  []struct {
+		Term string `xml:"term,attr"`
+		Text string `xml:",chardata"`
+	} `xml:"category"`
+	Links []struct {
+		Href   string `xml:"href,attr"`
+		Rel    string `xml:"rel,attr"`
+		Type   string `xml:"type,attr"`
+		Length string `xml:"length,attr"`
+		Text   string `xml:",chardata"`
+	} `xml:"link"`
+	Enclosures []struct {
+		URL    string `xml:"url,attr"`
+		Type   string `xml:"type,attr"`
+		Length string `xml:"length,attr"`
+	} `xml:"enclosure"`
+	Thumbnails []struct {
+		URL string `xml:"url,attr"`
+	} `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	Media []struct {
+		URL    string `xml:"url,attr"`
+		Type   string `xml:"type,attr"`
+		Medium string `xml:"medium,attr"`
+	} `xml:"http://search.yahoo.com/mrss/ content"`
+}
+
+// xmlActor is an RSS author, written as text, or an Atom person.
+type xmlActor struct {
+	Name string `xml:"name"`
+	Text string `xml:",chardata"`
+}
+
+// parseXMLFeed reads the item and entry elements one by one, so a feed cut
+// short still yields the items before the damage.
+func parseXMLFeed(data []byte) ([]map[string][]string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	raw := []map[string][]string{}
+	root := ""
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) && root != "" {
+				break
+			}
+			if len(raw) > 0 {
+				// Keep what could be read from a truncated or broken feed
+				break
+			}
+			if root == "" {
+				return nil, fmt.Errorf("not a feed: %w", err)
+			}
+			return nil, fmt.Errorf("error parsing the feed: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if root == "" {
+			root = start.Name.Local
+			switch root {
+			case "rss", "feed", "RDF":
+			default:
+				return nil, fmt.Errorf("not a feed: unexpected <%s> root element", root)
+			}
+			continue
+		}
+		if start.Name.Local != "item" && start.Name.Local != "entry" {
+			continue
+		}
+		var item xmlItem
+		if err := decoder.DecodeElement(&item, &start); err != nil {
+			if len(raw) > 0 {
+				break
+			}
+			return nil, fmt.Errorf("error parsing the feed: %w", err)
+		}
+		raw = append(raw, item.values())
+	}
+	return raw, nil
+}
+
+func (i *xmlItem) values() map[string][]string {
+	values := map[string][]string{
+		"title":   {plainText(i.Title.markup())},
+		"summary": {firstNonEmpty(i.Description.String(), i.Summary.String())},
+		"content": {firstNonEmpty(i.Encoded, i.Content.String())},
+		"guid":    {firstNonEmpty(i.GUID, i.ID)},
+		"date":    {firstNonEmpty(i.PubDate, i.Published, i.Issued, i.Date)},
+		"updated": {firstNonEmpty(i.Updated, i.Modified)},
+	}
+
+	for _, l := range i.Links {
+		if l.Rel == "enclosure" {
+			continue
+		}
+		if text := strings.TrimSpace(l.Text); text != "" {
+			values["link"] = []string{text}
+			break
+		}
+		if l.Href != "" && (l.Rel == "" || l.Rel == "alternate") {
+			values["link"] = []string{l.Href}
+			break
+		}
+	}
+
+	for _, author := range i.Authors {
+		values["author"] = append(values["author"], firstNonEmpty(author.Name, author.Text))
+	}
+	values["author"] = append(values["author"], i.Creators...)
+	for _, category := range i.Categories {
+		values["category"] = append(values["category"], firstNonEmpty(category.Term, category.Text))
+	}
+
+	for _, thumbnail := range i.Thumbnails {
+		values["image"] = append(values["image"], thumbnail.URL)
+	}
+	for _, media := range i.Media {
+		if media.Medium == "image" || strings.HasPrefix(media.Type, "image/") {
+			values["image"] = append(values["image"], media.URL)
+		}
+	}
+	for _, enclosure := range i.Enclosures {
+		if strings.HasPrefix(enclosure.Type, "image/") {
+			values["image"] = append(values["image"], enclosure.URL)
+		} else {
+			values["enclosure"] = append(values["enclosure"], enclosureValue(enclosure.URL, enclosure.Type, enclosure.Length))
+		}
+	}
+	for _, l := range i.Links {
+		switch {
+		case l.Rel != "enclosure":
+		case strings.HasPrefix(l.Type, "image/"):
+			values["image"] = append(values["image"], l.Href)
+		default:
+			values["enclosure"] = append(values["enclosure"], enclosureValue(l.Href, l.Type, l.Length))
+		}
+	}
+	return fillItem(values)
+}
+
+// enclosureValue returns the raw value of an enclosure: its URL followed by
+// its MIME type and length when known, see parseEnclosure.
+func enclosureValue(url, mimeType, length string) string {
+	value := strings.ReplaceAll(strings.TrimSpace(url), " ", "%20")
+	for _, attr := range []string{mimeType, length} {
+		if attr = strings.TrimSpace(attr); attr != "" && !strings.ContainsAny(attr, " \t") {
+			value += " " + attr
+		}
+	}
+	return value
+}
+
+// jsonFeed is the subset of JSON Feed 1.0 and 1.1 read from upstream feeds.
+type jsonFeed struct {
+	Items []struct {
+		ID            json.RawMessage `json:"id"` // a number in some feeds
+		URL           string          `json:"url"`
+		ExternalURL   string          `json:"external_url"`
+		Title         string          `json:"title"`
+		ContentHTML   string          `json:"content_html"`
+		ContentText   string          `json:"content_text"`
+		Summary       string          `json:"summary"`
+		Image         string          `json:"image"`
+		BannerImage   string          `json:"banner_image"`
+		DatePublished string          `json:"date_published"`
+		DateModified  string          `json:"date_modified"`
+		Tags          []string        `json:"tags"`
+		Author        *jsonAuthor     `json:"author"`
+		Authors       []jsonAuthor    `json:"authors"`
+		Attachments   []struct {
+			URL         string `json:"url"`
+			MimeType    string `json:"mime_type"`
+			SizeInBytes int64  `json:"size_in_bytes"`
+		} `json:"attachments"`
+	} `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+func parseJSONFeed(data []byte) ([]map[string][]string, error) {
+	var feed jsonFeed
+	if err := json.Unmarshal(data, &feed); err != nil {
+		return nil, fmt.Errorf("error parsing the feed: %w", err)
+	}
+	raw := make([]map[string][]string, 0, len(feed.Items))
+	for _, item := range feed.Items {
+		id := strings.Trim(string(item.ID), `"`)
+		values := map[string][]string{
+			"title":    {plainText(item.Title)},
+			"link":     {firstNonEmpty(item.URL, item.ExternalURL)},
+			"summary":  {firstNonEmpty(item.Summary, item.ContentText)},
+			"content":  {item.ContentHTML},
+			"guid":     {id},
+			"image":    {firstNonEmpty(item.Image, item.BannerImage)},
+			"date":     {item.DatePublished},
+			"updated":  {item.DateModified},
+			"category": item.Tags,
+		}
+		if item.Author != nil {
+			values["author"] = append(values["author"], item.Author.Name)
+		}
+		for _, author := range item.Authors {
+			values["author"] = append(values["author"], author.Name)
+		}
+		for _, attachment := range item.Attachments {
+			length := ""
+			if attachment.SizeInBytes > 0 {
+				length = strconv.FormatInt(attachment.SizeInBytes, 10)
+			}
+			values["enclosure"] = append(values["enclosure"], enclosureValue(attachment.URL, attachment.MimeType, length))
+		}
+		raw = append(raw, fillItem(values))
+	}
+	return raw, nil
+}
+
+// fillItem completes the values missing from an upstream item: the link
+// falls back to a guid that is a URL and the title to the start of the text.
+func fillItem(values map[string][]string) map[string][]string {
+	first := siteItem(values).first
+	if strings.TrimSpace(first("link")) == "" {
+		if guid := strings.TrimSpace(first("guid")); strings.HasPrefix(guid, "http://") || strings.HasPrefix(guid, "https://") {
+			values["link"] = []string{guid}
+		}
+	}
+	if strings.TrimSpace(first("title")) == "" {
+		text := plainText(firstNonEmpty(first("summary"), first("content")))
+		values["title"] = []string{truncate(text, maxTitleLength)}
+	}
+	return values
+}
+
+// plainText returns the text of an HTML fragment with its whitespace
+// collapsed.
+func plainText(s string) string {
+	if !strings.ContainsAny(s, "<&") {
+		return strings.Join(strings.Fields(s), " ")
+	}
+	nodes, err := html.ParseFragment(strings.NewReader(s), &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div})
+	if err != nil {
+		return strings.Join(strings.Fields(s), " ")
+	}
+	var b strings.Builder
+	for _, n := range nodes {
+		b.WriteString(textContent(n))
+		b.WriteString(" ")
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// truncate cuts s to at most max runes on a word boundary.
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	cut := string([]rune(s)[:max])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,204 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	feedService "rss-generator/services/feed"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:media="http://search.yahoo.com/mrss/">
+<channel>
+	<title>Upstream</title>
+	<item>
+		<title>Hello &amp; welcome&nbsp;<b>world</b></title>
+		<link>/posts/hello</link>
+		<description><![CDATA[<p>Short summary</p>]]></description>
+		<content:encoded><![CDATA[<p>Full content</p>]]></content:encoded>
+		<dc:creator>Jane Doe</dc:creator>
+		<category>go</category>
+		<category>rss</category>
+		<guid isPermaLink="false">hello-1</guid>
+		<pubDate>Fri, 27 Oct 2023 10:00:00 GMT</pubDate>
+		<media:thumbnail url="https://cdn.example.com/hello.png"/>
+		<enclosure url="/episodes/hello.mp3" type="audio/mpeg" length="1234"/>
+	</item>
+	<item>
+		<description>An item without a title nor a date</description>
+		<guid>https://www.example.com/posts/untitled</guid>
+	</item>
+	<item>
+		<title>Cut short</title>
+		<link>https://www.example.com/posts/cut`
+
+const testAtom = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:media="http://search.yahoo.com/mrss/">
+	<title>Upstream</title>
+	<id>urn:uuid:60a76c80-d399-11d9-b93C-0003939e0af6</id>
+	<entry>
+		<title type="html">Atom &lt;em&gt;entry&lt;/em&gt;</title>
+		<link rel="self" href="https://www.example.com/self"/>
+		<link rel="enclosure" href="https://cdn.example.com/atom.ogg" type="audio/ogg"/>
+		<link rel="alternate" href="https://www.example.com/posts/atom"/>
+		<id>tag:example.com,2023:atom</id>
+		<published>2023-10-27T10:00:00Z</published>
+		<updated>2023-10-28T10:00:00Z</updated>
+		<author><name>John Roe</name></author>
+		<category term="atom"/>
+		<summary>Atom summary</summary>
+		<content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Atom content</p></div></content>
+	</entry>
+</feed>`
+
+const testJSONFeed = `{
+	"version": "https://jsonfeed.org/version/1.1",
+	"title": "Upstream",
+	"items": [
+		{
+			"id": 42,
+			"url": "https://www.example.com/posts/json",
+			"title": "JSON item",
+			"content_html": "<p>JSON content</p>",
+			"date_published": "2023-10-27T10:00:00+02:00",
+			"authors": [{"name": "Jane Doe"}],
+			"tags": ["json"],
+			"image": "https://cdn.example.com/json.png",
+			"attachments": [{"url": "https://cdn.example.com/json.m4a", "mime_type": "audio/x-m4a", "size_in_bytes": 5678}]
+		}
+	]
+}`
+
+func TestParseFeed_RSS(t *testing.T) {
+	raw, err := parseFeed([]byte(testRSS))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, raw, 2, "the truncated item is dropped")
+
+	item := siteItem(raw[0])
+	assert.Equal(t, "Hello & welcome world", item.first("title"))
+	assert.Equal(t, "/posts/hello", item.first("link"))
+	assert.Equal(t, "<p>Short summary</p>", item.first("summary"))
+	assert.Equal(t, "<p>Full content</p>", item.first("content"))
+	assert.Equal(t, []string{"Jane Doe"}, item["author"])
+	assert.Equal(t, []string{"go", "rss"}, item["category"])
+	assert.Equal(t, "hello-1", item.first("guid"))
+	assert.Equal(t, "Fri, 27 Oct 2023 10:00:00 GMT", item.first("date"))
+	assert.Equal(t, "https://cdn.example.com/hello.png", item.first("image"))
+	assert.Equal(t, []string{"/episodes/hello.mp3 audio/mpeg 1234"}, item["enclosure"])
+
+	untitled := siteItem(raw[1])
+	assert.Equal(t, "An item without a title nor a date", untitled.first("title"))
+	assert.Equal(t, "https://www.example.com/posts/untitled", untitled.first("link"))
+}
+
+func TestParseFeed_Atom(t *testing.T) {
+	raw, err := parseFeed([]byte(testAtom))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, raw, 1)
+	item := siteItem(raw[0])
+	assert.Equal(t, "Atom entry", item.first("title"))
+	assert.Equal(t, "https://www.example.com/posts/atom", item.first("link"))
+	assert.Equal(t, "tag:example.com,2023:atom", item.first("guid"))
+	assert.Equal(t, "2023-10-27T10:00:00Z", item.first("date"))
+	assert.Equal(t, "2023-10-28T10:00:00Z", item.first("updated"))
+	assert.Equal(t, []string{"John Roe"}, item["author"])
+	assert.Equal(t, []string{"atom"}, item["category"])
+	assert.Equal(t, "Atom summary", item.first("summary"))
+	assert.Contains(t, item.first("content"), "<p>Atom content</p>")
+	assert.Equal(t, []string{"https://cdn.example.com/atom.ogg audio/ogg"}, item["enclosure"])
+}
+
+func TestParseFeed_JSON(t *testing.T) {
+	raw, err := parseFeed([]byte(testJSONFeed))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, raw, 1)
+	item := siteItem(raw[0])
+	assert.Equal(t, "JSON item", item.first("title"))
+	assert.Equal(t, "42", item.first("guid"))
+	assert.Equal(t, []string{"Jane Doe"}, item["author"])
+	assert.Equal(t, []string{"json"}, item["category"])
+	assert.Equal(t, "https://cdn.example.com/json.png", item.first("image"))
+	assert.Equal(t, []string{"https://cdn.example.com/json.m4a audio/x-m4a 5678"}, item["enclosure"])
+}
+
+func TestParseFeed_NotAFeed(t *testing.T) {
+	_, err := parseFeed([]byte(`<!DOCTYPE html><html><body>Not found</body></html>`))
+	assert.ErrorContains(t, err, "not a feed")
+
+	_, err = parseFeed([]byte(`{"items": [`))
+	assert.Error(t, err)
+}
+
+func TestSiteScraper_Scrape_Feed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(testRSS))
+	}))
+	defer server.Close()
+
+	def := &Definition{
+		ID:           "upstream",
+		Title:        "Upstream",
+		Link:         "https://www.example.com/",
+		URL:          server.URL + "/feed.xml",
+		Fetch:        FetchFeed,
+		DateFallback: DateFallbackNow,
+	}
+	if !assert.NoError(t, def.Validate()) {
+		return
+	}
+	scraper := NewSiteScraper(def, NewMockCache(), nil)
+	feed, err := scraper.Scrape(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, feed.Items, 2)
+
+	hello := feed.Items[0]
+	assert.Equal(t, server.URL+"/posts/hello", hello.Link)
+	assert.Equal(t, "hello-1", hello.GUID)
+	assert.Equal(t, time.Date(2023, 10, 27, 10, 0, 0, 0, time.UTC), hello.Published.UTC())
+	assert.Equal(t, "<p>Full content</p>", hello.Content)
+	assert.Equal(t, []feedService.Enclosure{{URL: server.URL + "/episodes/hello.mp3", Type: "audio/mpeg", Length: 1234}}, hello.Enclosures)
+	assert.Empty(t, feed.Items[1].Enclosures)
+
+	// Undated items get the scrape time with the "now" fallback
+	assert.Equal(t, feed.Updated, feed.Items[1].Published)
+}
+
+func TestSiteScraper_Scrape_Feed_Sanitized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/"><channel><title>Upstream</title>
+	<item>
+		<title>Unsafe</title>
+		<link>/posts/unsafe</link>
+		<description><![CDATA[<p onclick="steal()">Summary <script>steal()</script><a href="javascript:steal()">link</a></p>]]></description>
+		<content:encoded><![CDATA[<p><a href="related">Related</a><img src="/img.png" onerror="steal()"></p>]]></content:encoded>
+	</item>
+</channel></rss>`))
+	}))
+	defer server.Close()
+
+	def := &Definition{ID: "upstream", URL: server.URL + "/feed.xml", Fetch: FetchFeed}
+	if !assert.NoError(t, def.Validate()) {
+		return
+	}
+	feed, err := NewSiteScraper(def, NewMockCache(), nil).Scrape(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	item := feed.Items[0]
+	assert.Equal(t, `<p>Summary <a>link</a></p>`, item.Summary)
+	assert.Equal(t, `<p><a href="`+server.URL+`/posts/related">Related</a><img src="`+server.URL+`/img.png"/></p>`, item.Content, "relative links are resolved against the item link")
+}
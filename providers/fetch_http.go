@@ -0,0 +1,171 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+)
+
+// userAgent is sent by the HTTP fetcher unless the definition overrides it,
+// some sites refuse requests that do not look like they come from a browser.
+const userAgent = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+// htmlAccept is the Accept header of the requests of web pages.
+const htmlAccept = "text/html,application/xhtml+xml"
+
+// maxPageSize bounds the pages downloaded by the HTTP fetcher.
+const maxPageSize = 10 << 20
+
+// HTTPFetcher downloads the page and extracts the items from the HTML
+// without running any JavaScript. It is much cheaper than the browser but
+// only works for the sites that render their content on the server.
+type HTTPFetcher struct {
+	Client *http.Client // http.DefaultClient when nil
+}
+
+func (f *HTTPFetcher) Fetch(ctx context.Context, def *Definition) ([]map[string][]string, error) {
+	doc, err := f.get(ctx, def)
+	if err != nil {
+		return nil, err
+	}
+	if def.Ready != "" {
+		ready, err := cascadia.Compile(def.Ready)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ready selector: %w", err)
+		}
+		if ready.MatchFirst(doc) == nil {
+			return nil, fmt.Errorf("%q not found in the page", def.Ready)
+		}
+	}
+	return extractHTML(doc, def)
+}
+
+func (f *HTTPFetcher) get(ctx context.Context, def *Definition) (*html.Node, error) {
+	data, err := download(ctx, f.Client, def.URL, def.Headers, htmlAccept)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the page: %w", err)
+	}
+	return doc, nil
+}
+
+// download gets a page with the extra headers of a definition.
+func download(ctx context.Context, client *http.Client, url string, headers map[string]string, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", accept)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("%s downloaded in %s.", url, time.Since(start).Round(time.Millisecond))
+	return data, nil
+}
+
+// extractHTML is the Go counterpart of extractScript.
+func extractHTML(doc *html.Node, def *Definition) ([]map[string][]string, error) {
+	items, err := cascadia.Compile(def.Items)
+	if err != nil {
+		return nil, fmt.Errorf("invalid items selector: %w", err)
+	}
+	selectors := make(map[string]cascadia.Selector, len(def.Fields))
+	for name, field := range def.Fields {
+		if field.Selector == "" {
+			continue
+		}
+		sel, err := cascadia.Compile(field.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s selector: %w", name, err)
+		}
+		selectors[name] = sel
+	}
+
+	raw := []map[string][]string{}
+	for _, item := range items.MatchAll(doc) {
+		values := make(map[string][]string, len(def.Fields))
+		for name, field := range def.Fields {
+			sel, ok := selectors[name]
+			switch {
+			case !ok:
+				values[name] = []string{readNode(item, field)}
+			case field.Multiple:
+				values[name] = []string{}
+				for _, el := range sel.MatchAll(item) {
+					values[name] = append(values[name], readNode(el, field))
+				}
+			default:
+				if el := sel.MatchFirst(item); el != nil {
+					values[name] = []string{readNode(el, field)}
+				} else {
+					values[name] = []string{}
+				}
+			}
+		}
+		raw = append(raw, values)
+	}
+	return raw, nil
+}
+
+// readNode reads the value of a field from a matched element.
+func readNode(n *html.Node, field Field) string {
+	if field.Sibling {
+		if n.NextSibling == nil {
+			return ""
+		}
+		return textContent(n.NextSibling)
+	}
+	if field.Attr != "" {
+		for _, attr := range n.Attr {
+			if attr.Key == field.Attr {
+				return attr.Val
+			}
+		}
+		return ""
+	}
+	// Approximate innerText, which collapses the whitespace of the markup
+	return strings.Join(strings.Fields(textContent(n)), " ")
+}
+
+// textContent concatenates the text nodes under n, skipping scripts and styles.
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	if n.Type == html.ElementNode && (n.Data == "script" || n.Data == "style") {
+		return ""
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
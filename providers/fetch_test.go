@@ -0,0 +1,127 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPage = `<!DOCTYPE html>
+<html><body>
+<div class="post">
+	<a href="/news/hello">  Hello
+		world </a>
+	<span class="info"><b>Jane Doe</b> — 27 Oct 2023</span>
+	<span class="tag">go</span><span class="tag">rss</span>
+	<script>var ignored = true;</script>
+</div>
+<div class="post">
+	<a href="/news/second">Second</a>
+</div>
+</body></html>`
+
+func testHTTPDefinition(url string) *Definition {
+	return &Definition{
+		ID:      "example",
+		URL:     url,
+		Fetch:   FetchHTTP,
+		Items:   ".post",
+		Headers: map[string]string{"Accept-Language": "en-US"},
+		Fields: map[string]Field{
+			"title":    {Selector: "a"},
+			"link":     {Selector: "a", Attr: "href"},
+			"author":   {Selector: ".info b"},
+			"date":     {Selector: ".info b", Sibling: true, Strip: " — "},
+			"category": {Selector: ".tag", Multiple: true},
+		},
+	}
+}
+
+func TestHTTPFetcher_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "en-US", r.Header.Get("Accept-Language"))
+		assert.NotEmpty(t, r.Header.Get("User-Agent"))
+		w.Write([]byte(testPage))
+	}))
+	defer server.Close()
+
+	def := testHTTPDefinition(server.URL + "/news/")
+	raw, err := (&HTTPFetcher{}).Fetch(context.Background(), def)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, raw, 2)
+	assert.Equal(t, []string{"Hello world"}, raw[0]["title"])
+	assert.Equal(t, []string{"/news/hello"}, raw[0]["link"])
+	assert.Equal(t, []string{"Jane Doe"}, raw[0]["author"])
+	assert.Equal(t, []string{"go", "rss"}, raw[0]["category"])
+	assert.Equal(t, []string{}, raw[1]["author"])
+
+	item := def.normalize(raw[0])
+	assert.Equal(t, server.URL+"/news/hello", item.first("link"))
+	assert.Equal(t, "27 Oct 2023", item.first("date"))
+}
+
+func TestHTTPFetcher_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testPage))
+	}))
+	defer server.Close()
+
+	_, err := (&HTTPFetcher{}).Fetch(context.Background(), testHTTPDefinition(server.URL+"/missing"))
+	assert.ErrorContains(t, err, "404")
+
+	def := testHTTPDefinition(server.URL)
+	def.Ready = ".rendered-by-javascript"
+	_, err = (&HTTPFetcher{}).Fetch(context.Background(), def)
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestAutoFetcher_Fetch(t *testing.T) {
+	var browserCalls atomic.Int32
+	browser := FetcherFunc(func(ctx context.Context, def *Definition) ([]map[string][]string, error) {
+		browserCalls.Add(1)
+		return []map[string][]string{{"title": {"From the browser"}}}, nil
+	})
+	empty := FetcherFunc(func(ctx context.Context, def *Definition) ([]map[string][]string, error) {
+		return []map[string][]string{}, nil
+	})
+	failing := FetcherFunc(func(ctx context.Context, def *Definition) ([]map[string][]string, error) {
+		return nil, errors.New("forbidden")
+	})
+	working := FetcherFunc(func(ctx context.Context, def *Definition) ([]map[string][]string, error) {
+		return []map[string][]string{{"title": {"From HTTP"}}}, nil
+	})
+	def := testHTTPDefinition("https://www.example.com/")
+
+	raw, err := (&AutoFetcher{HTTP: working, Browser: browser}).Fetch(context.Background(), def)
+	assert.NoError(t, err)
+	assert.Equal(t, "From HTTP", raw[0]["title"][0])
+	assert.Equal(t, int32(0), browserCalls.Load())
+
+	for _, http := range []Fetcher{empty, failing} {
+		raw, err := (&AutoFetcher{HTTP: http, Browser: browser}).Fetch(context.Background(), def)
+		assert.NoError(t, err)
+		assert.Equal(t, "From the browser", raw[0]["title"][0])
+	}
+	assert.Equal(t, int32(2), browserCalls.Load())
+}
+
+func TestNewFetcher(t *testing.T) {
+	assert.IsType(t, &BrowserFetcher{}, newFetcher(&Definition{}, nil))
+	assert.IsType(t, &HTTPFetcher{}, newFetcher(&Definition{Fetch: FetchHTTP}, nil))
+	assert.IsType(t, &AutoFetcher{}, newFetcher(&Definition{Fetch: FetchAuto}, nil))
+
+	def := testHTTPDefinition("https://www.example.com/")
+	def.Fetch = "curl"
+	assert.ErrorContains(t, def.Validate(), "fetch mode")
+}
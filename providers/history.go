@@ -0,0 +1,119 @@
+package providers
+
+import (
+	"encoding/json"
+	"log"
+	feedService "rss-generator/services/feed"
+	"sort"
+	"time"
+)
+
+const (
+	defaultHistoryItems    = 50
+	defaultHistoryMaxItems = 500
+	defaultHistoryMaxAge   = 90 * 24 * time.Hour
+)
+
+// HistoryItem is an article kept in the history of a provider.
+type HistoryItem struct {
+	feedService.Article
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// limits returns the history limits of the definition with the defaults
+// applied.
+func (h History) limits() (items, maxItems int, maxAge time.Duration) {
+	items, maxItems, maxAge = h.Items, h.MaxItems, time.Duration(h.MaxAge)
+	if items == 0 {
+		items = defaultHistoryItems
+	}
+	if maxItems == 0 {
+		maxItems = defaultHistoryMaxItems
+	}
+	if maxAge == 0 {
+		maxAge = defaultHistoryMaxAge
+	}
+	return items, maxItems, maxAge
+}
+
+func (s *SiteScraper) historyKey() string {
+	return "history-" + s.Definition.ID
+}
+
+// History returns the items retained for the provider, the most recently
+// first seen first.
+func (s *SiteScraper) History() []HistoryItem {
+	value, ok := s.Cache.Get(s.historyKey())
+	if !ok {
+		return nil
+	}
+	var items []HistoryItem
+	if err := json.Unmarshal([]byte(value), &items); err != nil {
+		log.Printf("Ignoring unreadable `%s` cache", s.historyKey())
+		return nil
+	}
+	return items
+}
+
+// mergeHistory adds the scraped items to the history, keyed by GUID, and
+// replaces the items of the feed with the last ones of the history. Items
+// that left the page are kept until they exceed the retention limits.
+func (s *SiteScraper) mergeHistory(feed *feedService.Feed, now time.Time) {
+	served, maxItems, maxAge := s.Definition.History.limits()
+
+	previous := map[string]HistoryItem{}
+	old := s.History()
+	for _, item := range old {
+		previous[item.GUID] = item
+	}
+
+	merged := make([]HistoryItem, 0, len(feed.Items)+len(old))
+	seen := map[string]bool{}
+	for _, article := range feed.Items {
+		if seen[article.GUID] {
+			continue
+		}
+		seen[article.GUID] = true
+		item := HistoryItem{Article: article, FirstSeen: now, LastSeen: now}
+		if prev, ok := previous[article.GUID]; ok {
+			item.FirstSeen = prev.FirstSeen
+			// A publication date does not change, keeping the first one
+			// found holds still the dates computed from the scrape time,
+			// with the "now" fallback or relative dates.
+			if !prev.Published.IsZero() {
+				item.Published = prev.Published
+			}
+			if item.Content == "" {
+				item.Content = prev.Content
+			}
+		}
+		merged = append(merged, item)
+	}
+	for _, item := range old {
+		if seen[item.GUID] || now.Sub(item.FirstSeen) > maxAge {
+			continue
+		}
+		seen[item.GUID] = true
+		merged = append(merged, item)
+	}
+
+	// The items of the scrape come first, so new items keep the order of
+	// the page.
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].FirstSeen.After(merged[j].FirstSeen) })
+	if len(merged) > maxItems {
+		merged = merged[:maxItems]
+	}
+
+	if content, err := json.Marshal(merged); err == nil {
+		s.Cache.Set(s.historyKey(), string(content))
+	}
+
+	if len(merged) > served {
+		merged = merged[:served]
+	}
+	feed.Items = make([]feedService.Article, 0, len(merged))
+	for _, item := range merged {
+		feed.Items = append(feed.Items, item.Article)
+	}
+}
@@ -0,0 +1,112 @@
+package providers
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	feedService "rss-generator/services/feed"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func historyFeed(guids ...string) *feedService.Feed {
+	feed := &feedService.Feed{}
+	for _, guid := range guids {
+		feed.Items = append(feed.Items, feedService.Article{Title: guid, Link: "https://www.example.com/" + guid, GUID: guid})
+	}
+	return feed
+}
+
+func titles(feed *feedService.Feed) []string {
+	titles := []string{}
+	for _, item := range feed.Items {
+		titles = append(titles, item.Title)
+	}
+	return titles
+}
+
+func titlesOf(items []HistoryItem) []string {
+	titles := []string{}
+	for _, item := range items {
+		titles = append(titles, item.Title)
+	}
+	return titles
+}
+
+func TestSiteScraper_Scrape_History(t *testing.T) {
+	cache := NewMockCache()
+	scraper := testSiteScraper(cache)
+	var calls atomic.Int32
+	scraper.Fetcher = fakeFetch(&calls, nil)
+
+	for i := 0; i < 3; i++ {
+		_, err := scraper.Scrape(context.Background(), "true")
+		assert.NoError(t, err)
+	}
+	feed, err := scraper.Scrape(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Article 3", "Article 2", "Article 1"}, titles(feed))
+
+	// The history outlives the scraper
+	scraper = testSiteScraper(cache)
+	history := scraper.History()
+	assert.Len(t, history, 3)
+	assert.Equal(t, "Article 1", history[2].Title)
+	assert.False(t, history[2].FirstSeen.IsZero())
+}
+
+func TestSiteScraper_MergeHistory(t *testing.T) {
+	scraper := testSiteScraper(NewMockCache())
+	scraper.Definition.History = History{Items: 3, MaxItems: 4, MaxAge: Duration(24 * time.Hour)}
+	start := time.Date(2025, 4, 2, 12, 0, 0, 0, time.UTC)
+
+	feed := historyFeed("b", "a")
+	feed.Items[0].Content = "<p>Body of b</p>"
+	scraper.mergeHistory(feed, start)
+	assert.Equal(t, []string{"b", "a"}, titles(feed))
+
+	// a left the page, c and d are new and come first in the page order
+	feed = historyFeed("d", "c", "b")
+	scraper.mergeHistory(feed, start.Add(time.Hour))
+	assert.Equal(t, []string{"d", "c", "b"}, titles(feed), "only the last Items are served")
+	assert.Equal(t, "<p>Body of b</p>", feed.Items[2].Content, "previous content is kept")
+
+	history := scraper.History()
+	assert.Len(t, history, 4)
+	assert.Equal(t, "a", history[3].Title)
+	assert.Equal(t, start, history[3].FirstSeen)
+	assert.Equal(t, start, history[3].LastSeen)
+	assert.Equal(t, start.Add(time.Hour), history[2].LastSeen)
+	assert.Equal(t, start, history[2].FirstSeen)
+
+	// Retention by count drops the oldest items
+	feed = historyFeed("e")
+	scraper.mergeHistory(feed, start.Add(2*time.Hour))
+	history = scraper.History()
+	assert.Len(t, history, 4)
+	for _, item := range history {
+		assert.NotEqual(t, "a", item.Title)
+	}
+
+	// Retention by age drops the items first seen too long ago, unless
+	// they are still on the page
+	feed = historyFeed("b")
+	scraper.mergeHistory(feed, start.Add(25*time.Hour))
+	assert.Equal(t, []string{"e", "d", "c", "b"}, titlesOf(scraper.History()))
+	feed = historyFeed("f")
+	scraper.mergeHistory(feed, start.Add(50*time.Hour))
+	assert.Equal(t, []string{"f"}, titlesOf(scraper.History()))
+}
+
+func TestHistoryLimits(t *testing.T) {
+	items, maxItems, maxAge := History{}.limits()
+	assert.Equal(t, defaultHistoryItems, items)
+	assert.Equal(t, defaultHistoryMaxItems, maxItems)
+	assert.Equal(t, defaultHistoryMaxAge, maxAge)
+
+	def := testHTTPDefinition("https://www.example.com/")
+	def.History.MaxAge = Duration(-time.Hour)
+	assert.Error(t, def.Validate())
+}
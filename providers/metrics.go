@@ -0,0 +1,39 @@
+package providers
+
+import "sync/atomic"
+
+// Metrics counts how the feed requests of a provider were served.
+type Metrics struct {
+	CacheHits uint64 `json:"cacheHits"` // served from a fresh cache entry
+	StaleHits uint64 `json:"staleHits"` // served from an expired entry while refreshing
+	Misses    uint64 `json:"misses"`    // had to wait for a scrape
+	Coalesced uint64 `json:"coalesced"` // joined a scrape already in flight
+	Scrapes   uint64 `json:"scrapes"`   // scrapes actually started
+	Errors    uint64 `json:"errors"`    // scrapes that failed
+}
+
+// MetricsReporter is implemented by the scrapers that keep Metrics.
+type MetricsReporter interface {
+	Metrics() Metrics
+}
+
+// metrics holds the live counters behind Metrics.
+type metrics struct {
+	cacheHits atomic.Uint64
+	staleHits atomic.Uint64
+	misses    atomic.Uint64
+	coalesced atomic.Uint64
+	scrapes   atomic.Uint64
+	errors    atomic.Uint64
+}
+
+func (m *metrics) snapshot() Metrics {
+	return Metrics{
+		CacheHits: m.cacheHits.Load(),
+		StaleHits: m.staleHits.Load(),
+		Misses:    m.misses.Load(),
+		Coalesced: m.coalesced.Load(),
+		Scrapes:   m.scrapes.Load(),
+		Errors:    m.errors.Load(),
+	}
+}
@@ -0,0 +1,56 @@
+package providers
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrScrapeRunning is returned by Refresh while the provider is already
+// being scraped.
+var ErrScrapeRunning = errors.New("a scrape is already running")
+
+// RefreshResult is the outcome of an on-demand scrape, taken from its Run.
+type RefreshResult struct {
+	Outcome  string   `json:"outcome"` // RunSucceeded or RunFailed
+	Error    string   `json:"error,omitempty"`
+	Duration Duration `json:"duration"`
+	Found    int      `json:"found"`   // items found on the page
+	Items    int      `json:"items"`   // items of the new feed, history included
+	Added    []string `json:"added"`   // GUIDs missing from the previous run
+	Removed  []string `json:"removed"` // GUIDs of the previous run that are gone
+}
+
+// Refresher is implemented by the scrapers that can be refreshed on demand.
+type Refresher interface {
+	Refresh(ctx context.Context) (RefreshResult, error)
+}
+
+// Refresh scrapes the site like a job and compares the items found on the
+// page with the previous run. Unlike Scrape it never joins a scrape in
+// flight, it fails with ErrScrapeRunning instead. The result describes the
+// run even when the scrape fails, unless ctx is done before its end.
+func (s *SiteScraper) Refresh(ctx context.Context) (RefreshResult, error) {
+	s.mu.Lock()
+	if s.inflight != nil {
+		s.mu.Unlock()
+		return RefreshResult{}, ErrScrapeRunning
+	}
+	f := s.start(withDefaultTrigger(ctx, TriggerOnDemand))
+	s.mu.Unlock()
+
+	_, err := f.wait(ctx)
+	select {
+	case <-f.done:
+	default:
+		return RefreshResult{}, err
+	}
+	return RefreshResult{
+		Outcome:  f.run.Outcome,
+		Error:    f.run.Error,
+		Duration: Duration(f.run.Duration()),
+		Found:    f.run.Items,
+		Items:    f.run.Served,
+		Added:    f.run.Added,
+		Removed:  f.run.Removed,
+	}, err
+}
@@ -0,0 +1,51 @@
+package providers
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSiteScraper_Refresh(t *testing.T) {
+	cache := NewMockCache()
+	scraper := testSiteScraper(cache)
+	scraper.Definition.History.Items = 1
+	var calls atomic.Int32
+	scraper.Fetcher = fakeFetch(&calls, nil)
+
+	result, err := scraper.Refresh(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Items)
+	assert.Equal(t, []string{"https://www.example.com/article-1"}, result.Added)
+	assert.Empty(t, result.Removed)
+
+	result, err = scraper.Refresh(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://www.example.com/article-2"}, result.Added)
+	assert.Equal(t, []string{"https://www.example.com/article-1"}, result.Removed)
+	assert.Greater(t, result.Duration, Duration(0))
+}
+
+func TestSiteScraper_Refresh_Running(t *testing.T) {
+	scraper := testSiteScraper(NewMockCache())
+	var calls atomic.Int32
+	release := make(chan struct{})
+	scraper.Fetcher = fakeFetch(&calls, release)
+
+	done := make(chan error)
+	go func() {
+		_, err := scraper.Refresh(context.Background())
+		done <- err
+	}()
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	// A second refresh is refused instead of joining the first one
+	_, err := scraper.Refresh(context.Background())
+	assert.ErrorIs(t, err, ErrScrapeRunning)
+	close(release)
+	assert.NoError(t, <-done)
+	assert.Equal(t, int32(1), calls.Load())
+}
@@ -0,0 +1,187 @@
+package providers
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	browserService "rss-generator/services/browser"
+	cacheService "rss-generator/services/cache"
+	notifyService "rss-generator/services/notify"
+	"sort"
+	"sync"
+)
+
+//go:embed definitions/*.yaml
+var builtinDefinitions embed.FS
+
+// Registry keeps every known provider definition together with its scraper.
+type Registry struct {
+	cache    cacheService.Cacher
+	browser  *browserService.Pool
+	notifier notifyService.Notifier
+	mu       sync.RWMutex
+	defs     map[string]*Definition
+	scrapers map[string]Scraper
+	bundles  map[string]*Bundle
+}
+
+func NewRegistry(cache cacheService.Cacher, browser *browserService.Pool) *Registry {
+	return &Registry{
+		cache:    cache,
+		browser:  browser,
+		defs:     make(map[string]*Definition),
+		scrapers: make(map[string]Scraper),
+		bundles:  make(map[string]*Bundle),
+	}
+}
+
+// LoadBuiltin registers the definitions shipped with the binary.
+func (r *Registry) LoadBuiltin() error {
+	sub, err := fs.Sub(builtinDefinitions, "definitions")
+	if err != nil {
+		return err
+	}
+	return r.LoadFS(sub)
+}
+
+// LoadDir registers the definitions found in dir. A definition with the same
+// id as an already registered one replaces it.
+func (r *Registry) LoadDir(dir string) error {
+	return r.LoadFS(os.DirFS(dir))
+}
+
+func (r *Registry) LoadFS(fsys fs.FS) error {
+	defs, err := LoadDefinitions(fsys)
+	if err != nil {
+		return err
+	}
+	for _, def := range defs {
+		if err := r.Register(def); err != nil {
+			return err
+		}
+	}
+	bundles, err := loadBundles(fsys)
+	if err != nil {
+		return err
+	}
+	for _, bundle := range bundles {
+		if err := r.RegisterBundle(bundle); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Register adds or replaces a provider.
+func (r *Registry) Register(def *Definition) error {
+	if err := def.Validate(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.bundles[def.ID]; ok {
+		return fmt.Errorf("provider %s has the id of a bundle", def.ID)
+	}
+	if _, ok := r.defs[def.ID]; ok {
+		log.Printf("Replacing provider %s", def.ID)
+	}
+	r.defs[def.ID] = def
+	scraper := NewSiteScraper(def, r.cache, r.browser)
+	scraper.Notifier = r.notifier
+	r.scrapers[def.ID] = scraper
+	return nil
+}
+
+// SetNotifier sets the notifier alerted about the failing providers, the
+// registered ones included.
+func (r *Registry) SetNotifier(notifier notifyService.Notifier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notifier = notifier
+	for _, scraper := range r.scrapers {
+		if site, ok := scraper.(*SiteScraper); ok {
+			site.Notifier = notifier
+		}
+	}
+}
+
+// Definition returns the definition registered under id.
+func (r *Registry) Definition(id string) (*Definition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	def, ok := r.defs[id]
+	return def, ok
+}
+
+// Scraper returns the scraper registered under id.
+func (r *Registry) Scraper(id string) (Scraper, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	scraper, ok := r.scrapers[id]
+	return scraper, ok
+}
+
+// MustScraper is like Scraper but panics when id is unknown.
+func (r *Registry) MustScraper(id string) Scraper {
+	scraper, ok := r.Scraper(id)
+	if !ok {
+		panic(fmt.Sprintf("provider %s is not registered", id))
+	}
+	return scraper
+}
+
+// Definitions returns all registered definitions sorted by id.
+func (r *Registry) Definitions() []*Definition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	defs := make([]*Definition, 0, len(r.defs))
+	for _, def := range r.defs {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].ID < defs[j].ID })
+	return defs
+}
+
+// RegisterBundle adds or replaces a bundle. Its providers must be registered.
+func (r *Registry) RegisterBundle(bundle *Bundle) error {
+	if err := bundle.Validate(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.defs[bundle.ID]; ok {
+		return fmt.Errorf("bundle %s has the id of a provider", bundle.ID)
+	}
+	for _, id := range bundle.Providers {
+		if _, ok := r.defs[id]; !ok {
+			return fmt.Errorf("bundle %s has an unknown provider %s", bundle.ID, id)
+		}
+	}
+	if _, ok := r.bundles[bundle.ID]; ok {
+		log.Printf("Replacing bundle %s", bundle.ID)
+	}
+	r.bundles[bundle.ID] = bundle
+	return nil
+}
+
+// Bundle returns the bundle registered under id.
+func (r *Registry) Bundle(id string) (*Bundle, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	bundle, ok := r.bundles[id]
+	return bundle, ok
+}
+
+// Bundles returns all registered bundles sorted by id.
+func (r *Registry) Bundles() []*Bundle {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	bundles := make([]*Bundle, 0, len(r.bundles))
+	for _, bundle := range r.bundles {
+		bundles = append(bundles, bundle)
+	}
+	sort.Slice(bundles, func(i, j int) bool { return bundles[i].ID < bundles[j].ID })
+	return bundles
+}
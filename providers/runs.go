@@ -0,0 +1,146 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"slices"
+	"time"
+)
+
+// Triggers of a scrape, recorded in its Run.
+const (
+	TriggerCron      = "cron"       // scheduled job
+	TriggerStartup   = "startup"    // first scrape when the server starts
+	TriggerOnDemand  = "on-demand"  // admin refresh
+	TriggerCacheMiss = "cache-miss" // feed requested while not cached
+	TriggerStale     = "stale"      // background refresh of an expired feed
+)
+
+// Outcomes of a Run.
+const (
+	RunSucceeded = "success"
+	RunFailed    = "error"
+)
+
+// maxRuns is the number of runs kept per provider.
+const maxRuns = 100
+
+// Run records one scrape of a provider.
+type Run struct {
+	Trigger  string    `json:"trigger"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	Outcome  string    `json:"outcome"` // RunSucceeded or RunFailed
+	Error    string    `json:"error,omitempty"`
+	Items    int       `json:"items"`   // items found on the page
+	Served   int       `json:"served"`  // items of the feed, history included
+	GUIDs    []string  `json:"guids"`   // items found on the page
+	Added    []string  `json:"added"`   // GUIDs missing from the previous successful run
+	Removed  []string  `json:"removed"` // GUIDs of the previous successful run that are gone
+}
+
+// Duration returns how long the run took.
+func (r Run) Duration() time.Duration {
+	return r.Finished.Sub(r.Started)
+}
+
+// RunReporter is implemented by the scrapers that record their runs.
+type RunReporter interface {
+	Runs() []Run
+}
+
+type triggerKey struct{}
+
+// WithTrigger returns a context whose scrapes are recorded with the given
+// trigger. Without one, a job is recorded as TriggerCron and a feed
+// request as TriggerCacheMiss.
+func WithTrigger(ctx context.Context, trigger string) context.Context {
+	return context.WithValue(ctx, triggerKey{}, trigger)
+}
+
+// withDefaultTrigger sets the trigger of ctx unless it already has one.
+func withDefaultTrigger(ctx context.Context, trigger string) context.Context {
+	if _, ok := ctx.Value(triggerKey{}).(string); ok {
+		return ctx
+	}
+	return WithTrigger(ctx, trigger)
+}
+
+// background reports whether the scrapes of trigger run with no reader
+// waiting for them.
+func background(trigger string) bool {
+	switch trigger {
+	case TriggerCron, TriggerStartup, TriggerStale:
+		return true
+	}
+	return false
+}
+
+func triggerFrom(ctx context.Context) string {
+	trigger, _ := ctx.Value(triggerKey{}).(string)
+	return trigger
+}
+
+func (s *SiteScraper) runsKey() string {
+	return "runs-" + s.Definition.ID
+}
+
+// Runs returns the last runs of the provider, the most recent first. They
+// are stored in the cache, use a disk cache to keep them across restarts.
+func (s *SiteScraper) Runs() []Run {
+	value, ok := s.Cache.Get(s.runsKey())
+	if !ok {
+		return nil
+	}
+	var runs []Run
+	if err := json.Unmarshal([]byte(value), &runs); err != nil {
+		log.Printf("Ignoring unreadable `%s` cache", s.runsKey())
+		return nil
+	}
+	return runs
+}
+
+// recordRun compares a run with the previous successful one and stores it.
+// Runs are recorded by scrape, which never runs concurrently for a
+// provider.
+func (s *SiteScraper) recordRun(run *Run) {
+	runs := s.Runs()
+	if run.Outcome == RunSucceeded {
+		var previous []string
+		for _, r := range runs {
+			if r.Outcome == RunSucceeded {
+				previous = r.GUIDs
+				break
+			}
+		}
+		run.Added, run.Removed = diffGUIDs(previous, run.GUIDs)
+	}
+
+	runs = slices.Insert(runs, 0, *run)
+	if len(runs) > maxRuns {
+		runs = runs[:maxRuns]
+	}
+	content, err := json.Marshal(runs)
+	if err != nil {
+		log.Printf("Error encoding the runs of %s: %v", s.Definition.Title, err)
+		return
+	}
+	s.Cache.Set(s.runsKey(), string(content))
+}
+
+// diffGUIDs returns the GUIDs added to and removed from a list.
+func diffGUIDs(previous, current []string) (added, removed []string) {
+	added, removed = []string{}, []string{}
+	for _, guid := range current {
+		if !slices.Contains(previous, guid) {
+			added = append(added, guid)
+		}
+	}
+	for _, guid := range previous {
+		if !slices.Contains(current, guid) {
+			removed = append(removed, guid)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,76 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSiteScraper_Runs(t *testing.T) {
+	cache := NewMockCache()
+	scraper := testSiteScraper(cache)
+	var calls atomic.Int32
+	scraper.Fetcher = fakeFetch(&calls, nil)
+
+	_, err := scraper.Scrape(context.Background())
+	assert.NoError(t, err)
+	_, err = scraper.Scrape(context.Background(), "true")
+	assert.NoError(t, err)
+	_, err = scraper.Refresh(context.Background())
+	assert.NoError(t, err)
+	scraper.Fetcher = FetcherFunc(func(ctx context.Context, def *Definition) ([]map[string][]string, error) {
+		return nil, errors.New("timeout")
+	})
+	_, err = scraper.Scrape(WithTrigger(context.Background(), TriggerStartup), "true")
+	assert.Error(t, err)
+
+	runs := scraper.Runs()
+	if !assert.Len(t, runs, 4) {
+		return
+	}
+	var triggers []string
+	for _, run := range runs {
+		triggers = append(triggers, run.Trigger)
+	}
+	assert.Equal(t, []string{TriggerStartup, TriggerOnDemand, TriggerCron, TriggerCacheMiss}, triggers, "most recent first")
+
+	failed := runs[0]
+	assert.Equal(t, RunFailed, failed.Outcome)
+	assert.Equal(t, "timeout", failed.Error)
+	assert.Empty(t, failed.GUIDs)
+
+	// Every scrape finds one new article, the history serves the older ones
+	first, last := runs[3], runs[1]
+	assert.Equal(t, RunSucceeded, first.Outcome)
+	assert.Equal(t, []string{"https://www.example.com/article-1"}, first.Added)
+	assert.Equal(t, 1, last.Items)
+	assert.Equal(t, 3, last.Served)
+	assert.Equal(t, []string{"https://www.example.com/article-3"}, last.GUIDs)
+	assert.Equal(t, []string{"https://www.example.com/article-3"}, last.Added)
+	assert.Equal(t, []string{"https://www.example.com/article-2"}, last.Removed)
+	assert.False(t, last.Finished.Before(last.Started))
+}
+
+func TestSiteScraper_Runs_Limit(t *testing.T) {
+	scraper := testSiteScraper(NewMockCache())
+	for i := range maxRuns + 5 {
+		scraper.recordRun(&Run{Trigger: TriggerCron, Outcome: RunFailed, Error: fmt.Sprint(i)})
+	}
+	runs := scraper.Runs()
+	assert.Len(t, runs, maxRuns)
+	assert.Equal(t, fmt.Sprint(maxRuns+4), runs[0].Error)
+}
+
+func TestDiffGUIDs(t *testing.T) {
+	added, removed := diffGUIDs([]string{"a", "b", "c"}, []string{"b", "d"})
+	assert.Equal(t, []string{"d"}, added)
+	assert.Equal(t, []string{"a", "c"}, removed)
+
+	added, removed = diffGUIDs(nil, []string{"a"})
+	assert.Equal(t, []string{"a"}, added)
+	assert.Equal(t, []string{}, removed)
+}
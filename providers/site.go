@@ -0,0 +1,443 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/url"
+	articleService "rss-generator/services/article"
+	browserService "rss-generator/services/browser"
+	cacheService "rss-generator/services/cache"
+	feedService "rss-generator/services/feed"
+	notifyService "rss-generator/services/notify"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// siteItem holds the extracted values of one item, keyed by field name.
+type siteItem map[string][]string
+
+// first returns the first value of the field or an empty string.
+func (i siteItem) first(name string) string {
+	if values := i[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// SiteScraper scrapes any site described by a Definition.
+type SiteScraper struct {
+	Definition *Definition
+	Cache      cacheService.Cacher    // Interface for the cache
+	Fetcher    Fetcher                // loads the page and extracts the raw items
+	Notifier   notifyService.Notifier // alerted when the scrapes start failing and recover, may be nil
+
+	refreshing atomic.Bool
+	metrics    metrics
+
+	mu          sync.Mutex
+	inflight    *flight // scrape shared by concurrent callers
+	lastError   error   // error of the last failed scrape, see Status
+	lastErrorAt time.Time
+	failing     bool // the last scrape failed, see alert
+}
+
+// flight is a scrape in progress, its result is shared with every caller
+// that asked for the feed while it was running.
+type flight struct {
+	done chan struct{}
+	feed *feedService.Feed
+	run  Run
+	err  error
+}
+
+func NewSiteScraper(def *Definition, cache cacheService.Cacher, browser *browserService.Pool) *SiteScraper {
+	return &SiteScraper{Definition: def, Cache: cache, Fetcher: newFetcher(def, browser)}
+}
+
+func (s *SiteScraper) cacheKey() string {
+	return "rss-" + s.Definition.ID
+}
+
+// Scrape returns the cached feed of the site, scraping it when the cache is
+// empty or when isJob is set. An expired feed is served as is while a
+// single background scrape refreshes it. When the scrape of a request
+// fails, the last good feed is served instead, see Status for its age and
+// the error. The trigger recorded in the Run of the scrape is read from ctx,
+// see WithTrigger.
+func (s *SiteScraper) Scrape(ctx context.Context, isJob ...string) (*feedService.Feed, error) {
+	def := s.Definition
+	if len(isJob) > 0 {
+		ctx = withDefaultTrigger(ctx, TriggerCron)
+	} else {
+		if feed, entry, ok := s.cached(); ok {
+			if !entry.Expired() {
+				log.Printf("Hit `%s` cache", s.cacheKey())
+				s.metrics.cacheHits.Add(1)
+				return feed, nil
+			}
+			log.Printf("Hit expired `%s` cache, refreshing in the background", s.cacheKey())
+			s.metrics.staleHits.Add(1)
+			s.refresh(ctx)
+			return feed, nil
+		}
+		s.metrics.misses.Add(1)
+		ctx = withDefaultTrigger(ctx, TriggerCacheMiss)
+	}
+	feed, err := s.scrapeShared(ctx)
+	if err != nil && len(isJob) == 0 {
+		if last, ok := s.lastGood(); ok {
+			log.Printf("Serving the last good %s feed from %s: %v", def.Title, last.Updated.Format(time.DateTime), err)
+			return last, nil
+		}
+	}
+	return feed, err
+}
+
+// Metrics implements MetricsReporter.
+func (s *SiteScraper) Metrics() Metrics {
+	return s.metrics.snapshot()
+}
+
+// scrapeShared scrapes the site, or joins the scrape already in flight and
+// shares its result, so concurrent callers trigger a single navigation. The
+// scrape is not canceled with ctx, a caller that gives up does not abort it
+// for the others, see scrapeContext.
+func (s *SiteScraper) scrapeShared(ctx context.Context) (*feedService.Feed, error) {
+	s.mu.Lock()
+	f := s.inflight
+	if f != nil {
+		s.metrics.coalesced.Add(1)
+	} else {
+		f = s.start(ctx)
+	}
+	s.mu.Unlock()
+	return f.wait(ctx)
+}
+
+// start starts a scrape in the background. s.mu must be held.
+func (s *SiteScraper) start(ctx context.Context) *flight {
+	f := &flight{done: make(chan struct{})}
+	s.inflight = f
+	go func() {
+		scrapeCtx, cancel := s.scrapeContext(ctx)
+		defer cancel()
+		f.feed, f.run, f.err = s.scrape(scrapeCtx)
+		s.mu.Lock()
+		s.inflight = nil
+		s.mu.Unlock()
+		close(f.done)
+	}()
+	return f
+}
+
+// scrapeContext returns the context of a scrape started for ctx. It is not
+// canceled with ctx, a caller that gives up does not abort the scrape for
+// the others, but it keeps the deadline of ctx: the timeout of a cron job,
+// with the defaults of the cron service applied, bounds its scrape. Without
+// a deadline the timeout of the definition is used.
+func (s *SiteScraper) scrapeContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(detached, deadline)
+	}
+	return context.WithTimeout(detached, s.Definition.timeout())
+}
+
+// wait returns the result of the scrape, or the error of ctx when it is
+// done first.
+func (f *flight) wait(ctx context.Context) (*feedService.Feed, error) {
+	select {
+	case <-f.done:
+		return f.feed, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// cached returns the cached feed, even if it has expired.
+func (s *SiteScraper) cached() (*feedService.Feed, cacheService.Entry, bool) {
+	entry, ok := s.Cache.Lookup(s.cacheKey())
+	if !ok {
+		return nil, entry, false
+	}
+	var feed feedService.Feed
+	if err := json.Unmarshal([]byte(entry.Value), &feed); err != nil {
+		log.Printf("Ignoring unreadable `%s` cache", s.cacheKey())
+		return nil, entry, false
+	}
+	return &feed, entry, true
+}
+
+// refresh scrapes the site in the background unless a refresh is already
+// running.
+func (s *SiteScraper) refresh(ctx context.Context) {
+	if !s.refreshing.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer s.refreshing.Store(false)
+		if _, err := s.scrapeShared(WithTrigger(context.WithoutCancel(ctx), TriggerStale)); err != nil {
+			log.Printf("Error refreshing %s in the background: %v", s.Definition.Title, err)
+		}
+	}()
+}
+
+// scrape fetches the site, stores the feed in the cache and records the
+// run.
+func (s *SiteScraper) scrape(ctx context.Context) (*feedService.Feed, Run, error) {
+	run := Run{Trigger: triggerFrom(ctx), Started: time.Now()}
+	feed, guids, err := s.fetchFeed(ctx)
+	run.Finished = time.Now()
+	if err != nil {
+		run.Outcome, run.Error = RunFailed, err.Error()
+		run.Items, run.GUIDs = len(guids), guids
+	} else {
+		run.Outcome, run.Items, run.Served, run.GUIDs = RunSucceeded, len(guids), len(feed.Items), guids
+	}
+	s.recordRun(&run)
+	if !errors.Is(err, browserService.ErrPoolExhausted) {
+		// A busy browser says nothing about the health of the provider
+		s.alert(run)
+	}
+	return feed, run, err
+}
+
+// fetchFeed fetches the site and stores the feed in the cache. It returns
+// the GUIDs of the items found on the page along with the feed, even when
+// they fail the expectations of the definition and the cache is left
+// untouched.
+func (s *SiteScraper) fetchFeed(ctx context.Context) (*feedService.Feed, []string, error) {
+	def := s.Definition
+	log.Printf("Start scraping %s...", def.Title)
+	s.metrics.scrapes.Add(1)
+	raw, err := s.Fetcher.Fetch(ctx, def)
+	if err != nil {
+		s.metrics.errors.Add(1)
+		s.setError(err)
+		log.Printf("Error scraping %s: %v", def.Title, err)
+		return nil, nil, err
+	}
+
+	items := make([]siteItem, 0, len(raw))
+	for _, values := range raw {
+		if item := def.normalize(values); item != nil {
+			items = append(items, item)
+		}
+	}
+
+	feed := def.feed(items)
+	guids := make([]string, 0, len(feed.Items))
+	for _, item := range feed.Items {
+		guids = append(guids, item.GUID)
+	}
+	if err := def.validate(items); err != nil {
+		s.metrics.errors.Add(1)
+		s.setError(err)
+		log.Printf("Error scraping %s, keeping the previous feed: %v", def.Title, err)
+		return nil, guids, err
+	}
+
+	if def.Content.Extract {
+		s.fillContent(ctx, feed)
+	}
+	s.mergeHistory(feed, feed.Updated)
+	feed.Changed = feed.Updated
+	if previous, _, ok := s.cached(); ok && !previous.Changed.IsZero() && sameItems(previous.Items, feed.Items) {
+		feed.Changed = previous.Changed
+	}
+	if content, err := json.Marshal(feed); err == nil {
+		s.Cache.Set(s.cacheKey(), string(content), time.Duration(def.TTL))
+	}
+
+	log.Printf("%s feed scraped with %d items, serving %d.", def.Title, len(guids), len(feed.Items))
+	return feed, guids, nil
+}
+
+// sameItems reports whether two lists of items have the same GUIDs and
+// content. Dates are left out, a date that moves is already accounted for
+// by LastModified and the scrape time ones would move at every scrape.
+func sameItems(a, b []feedService.Article) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		left, right := a[i], b[i]
+		left.Published, left.Updated = time.Time{}, time.Time{}
+		right.Published, right.Updated = time.Time{}, time.Time{}
+		leftJSON, err := json.Marshal(left)
+		if err != nil {
+			return false
+		}
+		rightJSON, err := json.Marshal(right)
+		if err != nil || !bytes.Equal(leftJSON, rightJSON) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalize applies the field options to the raw values. Items without a
+// title or a link are dropped.
+func (d *Definition) normalize(raw map[string][]string) siteItem {
+	item := siteItem{}
+	for name, field := range d.Fields {
+		var values []string
+		for _, value := range raw[name] {
+			if field.Split != "" {
+				parts := strings.Split(value, field.Split)
+				if field.Index < 0 || field.Index >= len(parts) {
+					continue
+				}
+				value = parts[field.Index]
+			}
+			if field.Strip != "" {
+				value = strings.ReplaceAll(value, field.Strip, "")
+			}
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			values = append(values, value)
+			if !field.Multiple {
+				break
+			}
+		}
+		item[name] = values
+	}
+	if item.first("title") == "" || item.first("link") == "" {
+		return nil
+	}
+	link, err := d.resolve(item.first("link"))
+	if err != nil {
+		log.Printf("Warning: dropping %s item %q with an invalid link: %v", d.ID, item.first("title"), err)
+		return nil
+	}
+	item["link"] = []string{link}
+	return item
+}
+
+// resolve turns a possibly relative link into an absolute URL.
+func (d *Definition) resolve(link string) (string, error) {
+	base, err := url.Parse(d.URL)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(link)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// parseItemDate parses the date with the layouts and timezone of the
+// definition.
+func (d *Definition) parseItemDate(dateString string) (time.Time, error) {
+	parser := feedService.DateParser{Layouts: d.DateFormats}
+	if d.Timezone != "" {
+		loc, err := time.LoadLocation(d.Timezone)
+		if err != nil {
+			return time.Time{}, err
+		}
+		parser.Location = loc
+	}
+	return parser.Parse(dateString)
+}
+
+// article maps the extracted values to the canonical article.
+func (d *Definition) article(item siteItem, now time.Time) feedService.Article {
+	article := feedService.Article{
+		Title:      item.first("title"),
+		Link:       item.first("link"),
+		Summary:    item.first("summary"),
+		Content:    item.first("content"),
+		Authors:    item["author"],
+		Categories: item["category"],
+		GUID:       item.first("guid"),
+	}
+	if image := item.first("image"); image != "" {
+		if resolved, err := d.resolve(image); err == nil {
+			article.Image = resolved
+		}
+	}
+	for _, value := range item["enclosure"] {
+		if enclosure, ok := parseEnclosure(value); ok {
+			if resolved, err := d.resolve(enclosure.URL); err == nil {
+				enclosure.URL = resolved
+				article.Enclosures = append(article.Enclosures, enclosure)
+			}
+		}
+	}
+	if d.Fetch == FetchFeed {
+		// The markup of an upstream feed is third-party, it is cleaned like
+		// the extracted articles
+		base, _ := url.Parse(article.Link)
+		article.Summary = articleService.Sanitize(article.Summary, base)
+		article.Content = articleService.Sanitize(article.Content, base)
+	}
+	if article.GUID == "" {
+		article.GUID = article.Link
+		if guidURL, err := url.Parse(article.Link); err == nil {
+			article.GUID = guidURL.String()
+		}
+	}
+	for name, date := range map[string]*time.Time{"date": &article.Published, "updated": &article.Updated} {
+		if raw := item.first(name); raw != "" {
+			t, err := d.parseItemDate(raw)
+			if err != nil {
+				if d.DateFallback == DateFallbackNow {
+					log.Printf("Warning: %s item %s has an invalid %s, using the scrape time: %v", d.ID, article.Link, name, err)
+					*date = now
+				} else {
+					log.Printf("Warning: %s item %s has an invalid %s, leaving it empty: %v", d.ID, article.Link, name, err)
+				}
+				continue
+			}
+			*date = t
+		}
+	}
+	if article.Published.IsZero() && d.DateFallback == DateFallbackNow {
+		// The item has no date at all, as in some upstream feeds
+		article.Published = now
+	}
+	return article
+}
+
+// parseEnclosure reads the raw value of an enclosure field: a URL optionally
+// followed by the MIME type and the length in bytes of the media, separated
+// by spaces.
+func parseEnclosure(value string) (feedService.Enclosure, bool) {
+	parts := strings.Fields(value)
+	if len(parts) == 0 {
+		return feedService.Enclosure{}, false
+	}
+	enclosure := feedService.Enclosure{URL: parts[0]}
+	for _, part := range parts[1:] {
+		if length, err := strconv.ParseInt(part, 10, 64); err == nil && length > 0 {
+			enclosure.Length = length
+		} else if strings.Contains(part, "/") {
+			enclosure.Type = part
+		}
+	}
+	return enclosure, true
+}
+
+// feed builds the feed of the definition from the extracted items.
+func (d *Definition) feed(items []siteItem) *feedService.Feed {
+	feed := &feedService.Feed{
+		Title:       d.Title,
+		Link:        d.Link,
+		Description: d.Description,
+		Updated:     time.Now(),
+		Items:       make([]feedService.Article, 0, len(items)),
+	}
+	for _, item := range items {
+		feed.Items = append(feed.Items, d.article(item, feed.Updated))
+	}
+	return feed
+}
@@ -0,0 +1,368 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	cacheService "rss-generator/services/cache"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// MockCache is a mock implementation of the Cacher interface for testing.
+type MockCache struct {
+	mu      sync.Mutex
+	data    map[string]string
+	expires map[string]time.Time
+	err     error
+}
+
+func NewMockCache() *MockCache {
+	return &MockCache{
+		data:    make(map[string]string),
+		expires: make(map[string]time.Time),
+	}
+}
+
+func (m *MockCache) Get(key string) (string, bool) {
+	entry, ok := m.Lookup(key)
+	if !ok || entry.Expired() {
+		return "", false
+	}
+	return entry.Value, true
+}
+
+func (m *MockCache) Lookup(key string) (cacheService.Entry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return cacheService.Entry{}, false
+	}
+	value, ok := m.data[key]
+	return cacheService.Entry{Value: value, Expires: m.expires[key]}, ok
+}
+
+func (m *MockCache) Set(key, value string, ttl ...time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return
+	}
+	m.data[key] = value
+	delete(m.expires, key)
+	if len(ttl) > 0 && ttl[0] > 0 {
+		m.expires[key] = time.Now().Add(ttl[0])
+	}
+}
+
+func (m *MockCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	delete(m.expires, key)
+}
+
+// fakeFetch returns a fetcher serving one item per call and counting
+// the calls. When release is not nil every call waits for it.
+func fakeFetch(calls *atomic.Int32, release chan struct{}) FetcherFunc {
+	return func(ctx context.Context, def *Definition) ([]map[string][]string, error) {
+		n := calls.Add(1)
+		if release != nil {
+			<-release
+		}
+		return []map[string][]string{{
+			"title": {fmt.Sprintf("Article %d", n)},
+			"link":  {fmt.Sprintf("/article-%d", n)},
+		}}, nil
+	}
+}
+
+func testSiteScraper(cache cacheService.Cacher) *SiteScraper {
+	def := &Definition{
+		ID:    "example",
+		URL:   "https://www.example.com/",
+		Items: ".post",
+		Fields: map[string]Field{
+			"title": {Selector: "a"},
+			"link":  {Selector: "a", Attr: "href"},
+		},
+		TTL: Duration(time.Hour),
+	}
+	return NewSiteScraper(def, cache, nil)
+}
+
+func TestSiteScraper_Scrape_TTL(t *testing.T) {
+	cache := NewMockCache()
+	scraper := testSiteScraper(cache)
+	var calls atomic.Int32
+	scraper.Fetcher = fakeFetch(&calls, nil)
+
+	feed, err := scraper.Scrape(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "Article 1", feed.Items[0].Title)
+	assert.Equal(t, "https://www.example.com/article-1", feed.Items[0].Link)
+	entry, ok := cache.Lookup("rss-example")
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), entry.Expires, time.Minute)
+
+	// A fresh entry is served from the cache, a job always scrapes
+	feed, _ = scraper.Scrape(context.Background())
+	assert.Equal(t, "Article 1", feed.Items[0].Title)
+	feed, _ = scraper.Scrape(context.Background(), "true")
+	assert.Equal(t, "Article 2", feed.Items[0].Title)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestSiteScraper_Scrape_StaleWhileRevalidate(t *testing.T) {
+	cache := NewMockCache()
+	scraper := testSiteScraper(cache)
+	cache.Set("rss-example", `{"title":"stale","items":[{"title":"Stale Article"}]}`, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	scraper.Fetcher = fakeFetch(&calls, release)
+
+	// Every reader gets the stale feed right away while one refresh runs
+	for i := 0; i < 3; i++ {
+		feed, err := scraper.Scrape(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "Stale Article", feed.Items[0].Title)
+	}
+	close(release)
+	assert.Eventually(t, func() bool { return !scraper.refreshing.Load() }, time.Second, time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load())
+
+	feed, err := scraper.Scrape(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "Article 1", feed.Items[0].Title)
+}
+
+func TestParseDefinition(t *testing.T) {
+	yamlDef := []byte(`
+id: example
+title: Example
+link: https://www.example.com/
+items: .post
+fields:
+  title:
+    selector: h2
+  link:
+    selector: h2 a
+    attr: href
+schedule:
+  cron: "@hourly"
+  timeout: 5m
+`)
+	def, err := ParseDefinition("example.yaml", yamlDef)
+	assert.NoError(t, err)
+	assert.Equal(t, "example", def.ID)
+	assert.Equal(t, "https://www.example.com/", def.URL)
+	assert.Equal(t, "href", def.Fields["link"].Attr)
+	assert.Equal(t, "@hourly", def.Schedule.Cron)
+	assert.Equal(t, Duration(5*time.Minute), def.Schedule.Timeout)
+
+	jsonDef := []byte(`{"id": "example", "url": "https://www.example.com/", "items": ".post",
+		"fields": {"title": {"selector": "h2"}, "link": {"selector": "a", "attr": "href"}},
+		"schedule": {"jitter": "90s"}}`)
+	def, err = ParseDefinition("example.json", jsonDef)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://www.example.com/", def.Link)
+	assert.Equal(t, "example", def.Title)
+	assert.Equal(t, Duration(90*time.Second), def.Schedule.Jitter)
+
+	_, err = ParseDefinition("example.yaml", []byte("id: example\nurl: https://www.example.com/\n"))
+	assert.Error(t, err)
+	_, err = ParseDefinition("example.json", []byte(`{"id": "example", "schedule": {"timeout": "soon"}}`))
+	assert.Error(t, err)
+	_, err = ParseDefinition("example.txt", yamlDef)
+	assert.Error(t, err)
+}
+
+func TestLoadBuiltin(t *testing.T) {
+	registry := NewRegistry(NewMockCache(), nil)
+	assert.NoError(t, registry.LoadBuiltin())
+
+	var ids []string
+	for _, def := range registry.Definitions() {
+		ids = append(ids, def.ID)
+	}
+	assert.Equal(t, []string{"aws", "csstricks", "freecodecamp", "nodeweekly", "theverge"}, ids)
+}
+
+func TestDefinitionNormalize(t *testing.T) {
+	def := &Definition{
+		ID:    "example",
+		URL:   "https://www.example.com/news/",
+		Items: ".post",
+		Fields: map[string]Field{
+			"title":    {Selector: "a"},
+			"link":     {Selector: "a", Attr: "href"},
+			"author":   {Selector: ".info", Split: ",", Index: 0},
+			"date":     {Selector: ".info", Split: ",", Index: 1, Strip: " — "},
+			"category": {Selector: ".tag", Multiple: true},
+		},
+	}
+
+	item := def.normalize(map[string][]string{
+		"title":    {"  Hello  "},
+		"link":     {"/news/hello"},
+		"author":   {"Jane Doe, — 27 Oct 2023"},
+		"date":     {"Jane Doe, — 27 Oct 2023"},
+		"category": {"go", " ", "rss"},
+	})
+	assert.Equal(t, "Hello", item.first("title"))
+	assert.Equal(t, "https://www.example.com/news/hello", item.first("link"))
+	assert.Equal(t, "Jane Doe", item.first("author"))
+	assert.Equal(t, "27 Oct 2023", item.first("date"))
+	assert.Equal(t, []string{"go", "rss"}, item["category"])
+
+	assert.Nil(t, def.normalize(map[string][]string{"link": {"/news/hello"}}))
+	assert.Nil(t, def.normalize(map[string][]string{"title": {"Broken"}, "link": {"http://exa mple.com/%zz"}}))
+}
+
+func TestSiteScraper_Scrape_InvalidLink(t *testing.T) {
+	scraper := testSiteScraper(NewMockCache())
+	scraper.Fetcher = FetcherFunc(func(ctx context.Context, def *Definition) ([]map[string][]string, error) {
+		return []map[string][]string{
+			{"title": {"Broken"}, "link": {"http://exa mple.com/%zz"}},
+			{"title": {"Fine"}, "link": {"/fine"}},
+		}, nil
+	})
+
+	feed, err := scraper.Scrape(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Fine"}, titles(feed))
+
+	// An item built without normalize keeps its raw link as GUID
+	article := scraper.Definition.article(siteItem{"title": {"Broken"}, "link": {"http://exa mple.com/%zz"}}, time.Now())
+	assert.Equal(t, "http://exa mple.com/%zz", article.GUID)
+}
+
+func TestExtractScript(t *testing.T) {
+	def := newTestScraper(t, "csstricks", NewMockCache()).Definition
+	script, err := extractScript(def)
+	assert.NoError(t, err)
+	assert.Contains(t, script, `".latest-articles .article-card"`)
+	assert.Contains(t, script, `a[rel=\"tag\"]`)
+}
+
+func TestDefinitionDateFallback(t *testing.T) {
+	now := time.Date(2025, 4, 2, 13, 0, 0, 0, time.UTC)
+	def := &Definition{ID: "example", URL: "https://www.example.com/", Timezone: "Asia/Tokyo"}
+	item := siteItem{"title": {"Hello"}, "link": {"https://www.example.com/hello"}, "date": {"not a date"}}
+
+	assert.True(t, def.article(item, now).Published.IsZero())
+
+	def.DateFallback = DateFallbackNow
+	assert.Equal(t, now, def.article(item, now).Published)
+
+	item["date"] = []string{"2025-04-02 09:00:00"}
+	assert.True(t, time.Date(2025, 4, 2, 0, 0, 0, 0, time.UTC).Equal(def.article(item, now).Published))
+
+	def.DateFallback = "later"
+	assert.Error(t, def.Validate())
+}
+
+func TestSiteScraper_Scrape_Coalesced(t *testing.T) {
+	scraper := testSiteScraper(NewMockCache())
+	var calls atomic.Int32
+	release := make(chan struct{})
+	scraper.Fetcher = fakeFetch(&calls, release)
+
+	var wg sync.WaitGroup
+	results := make(chan string, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			feed, err := scraper.Scrape(context.Background())
+			assert.NoError(t, err)
+			results <- feed.Items[0].Title
+		}()
+	}
+	// Wait until every reader is either scraping or waiting for the scrape
+	assert.Eventually(t, func() bool {
+		m := scraper.Metrics()
+		return m.Misses == 5 && m.Coalesced == 4
+	}, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	for title := range results {
+		assert.Equal(t, "Article 1", title)
+	}
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, Metrics{Misses: 5, Coalesced: 4, Scrapes: 1}, scraper.Metrics())
+
+	_, err := scraper.Scrape(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), scraper.Metrics().CacheHits)
+}
+
+func TestSiteScraper_Scrape_Deadline(t *testing.T) {
+	scraper := testSiteScraper(NewMockCache())
+	deadlines := make(chan time.Time, 1)
+	scraper.Fetcher = FetcherFunc(func(ctx context.Context, def *Definition) ([]map[string][]string, error) {
+		deadline, _ := ctx.Deadline()
+		deadlines <- deadline
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	// The deadline of a cron job bounds the scrape, not the provider timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	expected, _ := ctx.Deadline()
+	_, err := scraper.Scrape(ctx, "true")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, expected, <-deadlines)
+}
+
+func TestSiteScraper_Scrape_Changed(t *testing.T) {
+	scraper := testSiteScraper(NewMockCache())
+	items := []map[string][]string{{"title": {"First"}, "link": {"/first"}, "date": {"2023-10-27"}}}
+	scraper.Definition.Fields["date"] = Field{Selector: "time"}
+	scraper.Fetcher = FetcherFunc(func(ctx context.Context, def *Definition) ([]map[string][]string, error) {
+		return items, nil
+	})
+
+	first, err := scraper.Scrape(context.Background(), "true")
+	assert.NoError(t, err)
+	assert.Equal(t, first.Updated, first.Changed)
+	assert.Equal(t, first.Updated, first.LastModified(), "the items are older than the scrape")
+
+	// The same items keep the date of the change
+	second, err := scraper.Scrape(context.Background(), "true")
+	assert.NoError(t, err)
+	assert.True(t, second.Changed.Equal(first.Changed))
+
+	// An item of the same day found later changes it
+	items = append(items, map[string][]string{"title": {"Second"}, "link": {"/second"}, "date": {"2023-10-27"}})
+	third, err := scraper.Scrape(context.Background(), "true")
+	assert.NoError(t, err)
+	assert.Equal(t, third.Updated, third.Changed)
+	assert.True(t, third.LastModified().After(first.LastModified()))
+}
+
+func TestSiteScraper_Scrape_Changed_ScrapeTimeDates(t *testing.T) {
+	scraper := testSiteScraper(NewMockCache())
+	scraper.Definition.DateFallback = DateFallbackNow
+	scraper.Fetcher = FetcherFunc(func(ctx context.Context, def *Definition) ([]map[string][]string, error) {
+		return []map[string][]string{{"title": {"Undated"}, "link": {"/undated"}}}, nil
+	})
+
+	first, err := scraper.Scrape(context.Background(), "true")
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	second, err := scraper.Scrape(context.Background(), "true")
+	assert.NoError(t, err)
+	assert.True(t, second.Updated.After(first.Updated))
+	assert.True(t, second.Changed.Equal(first.Changed), "the items did not change")
+	assert.True(t, second.Items[0].Published.Equal(first.Items[0].Published), "the item keeps the date it was first seen")
+	assert.True(t, second.LastModified().Equal(first.LastModified()))
+}
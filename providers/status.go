@@ -0,0 +1,124 @@
+package providers
+
+import (
+	"context"
+	"log"
+	feedService "rss-generator/services/feed"
+	notifyService "rss-generator/services/notify"
+	"time"
+)
+
+// notifyTimeout bounds the delivery of an alert.
+const notifyTimeout = 30 * time.Second
+
+// Status describes the feed of a provider and its last scrapes.
+type Status struct {
+	LastSuccess time.Time `json:"lastSuccess"`         // date of the last good feed, zero before the first scrape
+	LastError   string    `json:"lastError,omitempty"` // error of the last failed scrape
+	LastErrorAt time.Time `json:"lastErrorAt"`
+	Failing     bool      `json:"failing"` // the last scrape failed, the feed is the last good one
+	Items       int       `json:"items"`   // items of the last good feed
+}
+
+// StatusReporter is implemented by the scrapers that report their Status.
+type StatusReporter interface {
+	Status() Status
+	// Failing returns the error of the last scrape when it failed, it is
+	// cheaper than Status.
+	Failing() (string, bool)
+}
+
+// Status implements StatusReporter. The date and the items come from the
+// last good feed in the cache, so they survive restarts with a disk cache.
+func (s *SiteScraper) Status() Status {
+	var status Status
+	if feed, ok := s.lastGood(); ok {
+		status.LastSuccess = feed.Updated
+		status.Items = len(feed.Items)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lastError != nil {
+		status.LastError = s.lastError.Error()
+		status.LastErrorAt = s.lastErrorAt
+	}
+	status.Failing = s.failing
+	return status
+}
+
+// lastGood returns the last feed produced, even expired: the cached one, or
+// one rebuilt from the history when the cache entry is gone.
+func (s *SiteScraper) lastGood() (*feedService.Feed, bool) {
+	if feed, _, ok := s.cached(); ok {
+		return feed, true
+	}
+	history := s.History()
+	if len(history) == 0 {
+		return nil, false
+	}
+	served, _, _ := s.Definition.History.limits()
+	feed := s.Definition.feed(nil)
+	feed.Updated = time.Time{}
+	for i, item := range history {
+		if item.LastSeen.After(feed.Updated) {
+			feed.Updated = item.LastSeen
+		}
+		if item.FirstSeen.After(feed.Changed) {
+			feed.Changed = item.FirstSeen
+		}
+		if i < served {
+			feed.Items = append(feed.Items, item.Article)
+		}
+	}
+	return feed, true
+}
+
+// Failing implements StatusReporter.
+func (s *SiteScraper) Failing() (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.failing || s.lastError == nil {
+		return "", false
+	}
+	return s.lastError.Error(), true
+}
+
+// setError records the error of a failed scrape.
+func (s *SiteScraper) setError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastError = err
+	s.lastErrorAt = time.Now()
+}
+
+// alert notifies the Notifier when the scrapes of the provider start
+// failing, and when they recover. A failing provider is only reported once.
+func (s *SiteScraper) alert(run Run) {
+	failing := run.Outcome == RunFailed
+	s.mu.Lock()
+	changed := failing != s.failing
+	s.failing = failing
+	s.mu.Unlock()
+	notifier := s.Notifier
+	if !changed || notifier == nil {
+		return
+	}
+
+	alert := notifyService.Alert{
+		Kind:     notifyService.AlertRecovered,
+		Provider: s.Definition.ID,
+		Title:    s.Definition.Title,
+		URL:      s.Definition.URL,
+		Time:     run.Finished,
+	}
+	if failing {
+		alert.Kind, alert.Error = notifyService.AlertFailing, run.Error
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		if err := notifier.Notify(ctx, alert); err != nil {
+			log.Printf("Error notifying that %s is %s: %v", s.Definition.Title, alert.Kind, err)
+		}
+	}()
+}
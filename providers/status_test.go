@@ -0,0 +1,83 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSiteScraper_Status(t *testing.T) {
+	cache := NewMockCache()
+	scraper := testSiteScraper(cache)
+	var calls atomic.Int32
+	scraper.Fetcher = fakeFetch(&calls, nil)
+
+	assert.Equal(t, Status{}, scraper.Status())
+
+	_, err := scraper.Scrape(context.Background())
+	assert.NoError(t, err)
+	status := scraper.Status()
+	assert.WithinDuration(t, time.Now(), status.LastSuccess, time.Minute)
+	assert.Equal(t, 1, status.Items)
+	assert.Empty(t, status.LastError)
+
+	// A failed scrape keeps the date and the items of the cached feed
+	scraper.Fetcher = FetcherFunc(func(ctx context.Context, def *Definition) ([]map[string][]string, error) {
+		return nil, errors.New("selector not found")
+	})
+	_, err = scraper.Scrape(context.Background(), "true")
+	assert.Error(t, err)
+	failed := scraper.Status()
+	assert.Equal(t, status.LastSuccess, failed.LastSuccess)
+	assert.Equal(t, 1, failed.Items)
+	assert.Equal(t, "selector not found", failed.LastError)
+	assert.False(t, failed.LastErrorAt.Before(failed.LastSuccess))
+}
+
+func TestSiteScraper_Scrape_LastGood(t *testing.T) {
+	cache := NewMockCache()
+	scraper := testSiteScraper(cache)
+	var calls atomic.Int32
+	scraper.Fetcher = fakeFetch(&calls, nil)
+	broken := FetcherFunc(func(ctx context.Context, def *Definition) ([]map[string][]string, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	_, err := scraper.Scrape(context.Background())
+	assert.NoError(t, err)
+	_, err = scraper.Scrape(context.Background(), "true")
+	assert.NoError(t, err)
+	_, failing := scraper.Failing()
+	assert.False(t, failing)
+
+	// Jobs report the failure, requests get the last good feed
+	scraper.Fetcher = broken
+	_, err = scraper.Scrape(context.Background(), "true")
+	assert.EqualError(t, err, "connection refused")
+	lastError, failing := scraper.Failing()
+	assert.True(t, failing)
+	assert.Equal(t, "connection refused", lastError)
+	assert.True(t, scraper.Status().Failing)
+
+	// Without the cache entry the feed is rebuilt from the history
+	cache.Delete("rss-example")
+	feed, err := scraper.Scrape(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Article 2", "Article 1"}, titles(feed))
+	assert.WithinDuration(t, time.Now(), feed.Updated, time.Minute)
+
+	// There is no feed to fall back to
+	cache.Delete("history-example")
+	_, err = scraper.Scrape(context.Background())
+	assert.EqualError(t, err, "connection refused")
+
+	scraper.Fetcher = fakeFetch(&calls, nil)
+	_, err = scraper.Scrape(context.Background(), "true")
+	assert.NoError(t, err)
+	_, failing = scraper.Failing()
+	assert.False(t, failing)
+}
@@ -2,9 +2,11 @@ package providers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	cacheService "rss-generator/services/cache"
+	feedService "rss-generator/services/feed"
 	"testing"
 	"time"
 
@@ -12,67 +14,53 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-// MockCache is a mock implementation of the Cacher interface for testing.
-type MockCache struct {
-	data map[string]string
-	err  error
-}
-
-func NewMockCache() *MockCache {
-	return &MockCache{
-		data: make(map[string]string),
-	}
-}
-
-func (m *MockCache) Get(key string) (string, bool) {
-	if m.err != nil {
-		return "", false
-	}
-	value, ok := m.data[key]
-	return value, ok
-}
+const cacheKeyTheVerge = "rss-theverge"
 
-func (m *MockCache) Set(key, value string) {
-	if m.err != nil {
-		return
-	}
-	m.data[key] = value
-}
-
-func (m *MockCache) Delete(key string) {
-	delete(m.data, key)
+// newTestScraper returns the scraper of a builtin definition.
+func newTestScraper(t *testing.T, id string, cache cacheService.Cacher) *SiteScraper {
+	registry := NewRegistry(cache, nil)
+	assert.NoError(t, registry.LoadBuiltin())
+	scraper, ok := registry.Scraper(id)
+	assert.True(t, ok)
+	return scraper.(*SiteScraper)
 }
 
 func TestNewTheVergeScraper(t *testing.T) {
 	cache := cacheService.NewMemoryCache()
-	scraper := NewTheVergeScraper(cache)
+	scraper := newTestScraper(t, "theverge", cache)
 	assert.NotNil(t, scraper)
 	assert.Equal(t, cache, scraper.Cache)
+	assert.Equal(t, "https://www.theverge.com/", scraper.Definition.URL)
 }
 
 func TestTheVergeScraper_Scrape_Cached(t *testing.T) {
 	mockCache := NewMockCache()
-	mockCache.data[cacheKeyTheVerge] = "cached-xml-data"
-	scraper := NewTheVergeScraper(mockCache)
+	mockCache.data[cacheKeyTheVerge] = `{"title":"cached","items":[{"title":"Cached Article","guid":"cached-guid"}]}`
+	scraper := newTestScraper(t, "theverge", mockCache)
 
 	ctx, cancel := chromedp.NewContext(context.Background())
 	defer cancel()
 
 	result, err := scraper.Scrape(ctx)
 	assert.NoError(t, err)
-	assert.Equal(t, "cached-xml-data", result)
+	assert.Equal(t, "cached", result.Title)
+	assert.Equal(t, "Cached Article", result.Items[0].Title)
 }
 
 func TestTheVergeScraper_Scrape_NotCached(t *testing.T) {
 	mockCache := NewMockCache()
-	scraper := NewTheVergeScraper(mockCache)
+	scraper := newTestScraper(t, "theverge", mockCache)
 
 	ctx, cancel := chromedp.NewContext(context.Background())
 	defer cancel()
 
-	result, err := scraper.Scrape(ctx)
+	feed, err := scraper.Scrape(ctx)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotEmpty(t, feed.Items)
+	result, err := feedService.Build(feedService.FormatRSS, feed, feedService.Options{})
 	assert.NoError(t, err)
-	assert.NotEmpty(t, result)
 	assert.Contains(t, result, "<rss")
 	assert.Contains(t, result, "<channel")
 	assert.Contains(t, result, "<item")
@@ -83,12 +71,13 @@ func TestTheVergeScraper_Scrape_NotCached(t *testing.T) {
 	assert.Contains(t, result, "<description>")
 	assert.Contains(t, result, "<pubDate>")
 	assert.Contains(t, result, "<guid>")
-	assert.Equal(t, result, mockCache.data[cacheKeyTheVerge])
+	cached, _ := json.Marshal(feed)
+	assert.Equal(t, string(cached), mockCache.data[cacheKeyTheVerge])
 }
 
 func TestTheVergeScraper_Scrape_Error(t *testing.T) {
 	mockCache := NewMockCache()
-	scraper := NewTheVergeScraper(mockCache)
+	scraper := newTestScraper(t, "theverge", mockCache)
 
 	// Create a context with a very short timeout to force an error
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Millisecond)
@@ -96,20 +85,22 @@ func TestTheVergeScraper_Scrape_Error(t *testing.T) {
 
 	result, err := scraper.Scrape(ctx)
 	assert.Error(t, err)
-	assert.Empty(t, result)
+	assert.Nil(t, result)
 }
 
 func TestTheVergeScraper_Scrape_SetCacheError(t *testing.T) {
 	mockCache := NewMockCache()
 	mockCache.err = errors.New("set cache error")
-	scraper := NewTheVergeScraper(mockCache)
+	scraper := newTestScraper(t, "theverge", mockCache)
 
 	ctx, cancel := chromedp.NewContext(context.Background())
 	defer cancel()
 
 	result, err := scraper.Scrape(ctx)
-	assert.NoError(t, err)
-	assert.NotEmpty(t, result)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotEmpty(t, result.Items)
 	assert.NotContains(t, mockCache.data, cacheKeyTheVerge)
 }
 
@@ -117,65 +108,63 @@ func TestParseVergeDate(t *testing.T) {
 	testCases := []struct {
 		name           string
 		dateString     string
-		expected       string
+		expected       time.Time
 		expectingError bool
 	}{
 		{
 			name:           "Valid Date",
 			dateString:     "2025-04-02T13:05:50+00:00",
-			expected:       "2025-04-02 13:05:50",
+			expected:       time.Date(2025, 4, 2, 13, 5, 50, 0, time.UTC),
 			expectingError: false,
 		},
 		{
 			name:           "Invalid Date",
 			dateString:     "invalid-date",
-			expected:       time.Now().Format(time.DateTime),
-			expectingError: false,
+			expected:       time.Time{},
+			expectingError: true,
 		},
 		{
 			name:           "Date without timezone",
 			dateString:     "2023-10-28T10:00:00",
-			expected:       "2023-10-28 10:00:00",
+			expected:       time.Date(2023, 10, 28, 10, 0, 0, 0, time.UTC),
 			expectingError: false,
 		},
 	}
 
+	def := newTestScraper(t, "theverge", NewMockCache()).Definition
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result, err := parseVergeDate(tc.dateString)
+			result, err := def.parseItemDate(tc.dateString)
 			if tc.expectingError {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
-				if tc.name == "Invalid Date" {
-					// For invalid date, we only check the format, not the exact time
-					_, err := time.Parse(time.DateTime, result)
-					assert.NoError(t, err)
-				} else {
-					assert.Equal(t, tc.expected, result)
-				}
+				assert.True(t, tc.expected.Equal(result))
 			}
 		})
 	}
 }
 
 func TestGeneratedTheVergeFeed(t *testing.T) {
-	articles := []VergeArticle{
+	def := *newTestScraper(t, "theverge", NewMockCache()).Definition
+	def.Title, def.Link, def.Description = "Test Feed", "https://www.example.com", "Test Description"
+	items := []siteItem{
 		{
-			Title:   "Test Article 1",
-			Link:    "https://www.example.com/article1",
-			Summary: "Summary 1",
-			Date:    "2023-10-27T10:00:00+00:00",
+			"title":   {"Test Article 1"},
+			"link":    {"https://www.example.com/article1"},
+			"summary": {"Summary 1"},
+			"date":    {"2023-10-27T10:00:00+00:00"},
 		},
 		{
-			Title:   "Test Article 2",
-			Link:    "https://www.example.com/article2",
-			Summary: "Summary 2",
-			Date:    "2023-10-28T12:00:00+00:00",
+			"title":   {"Test Article 2"},
+			"link":    {"https://www.example.com/article2"},
+			"summary": {"Summary 2"},
+			"date":    {"2023-10-28T12:00:00+00:00"},
 		},
 	}
 
-	xmlStr := generatedTheVergeFeed("Test Feed", "https://www.example.com", "Test Description", articles)
+	xmlStr, err := feedService.Build(feedService.FormatRSS, def.feed(items), feedService.Options{})
+	assert.NoError(t, err)
 	fmt.Println(xmlStr)
 	assert.NotEmpty(t, xmlStr)
 	assert.Contains(t, xmlStr, "<rss")
@@ -190,4 +179,5 @@ func TestGeneratedTheVergeFeed(t *testing.T) {
 	assert.Contains(t, xmlStr, "<title>Test Article 2</title>")
 	assert.Contains(t, xmlStr, "<link>https://www.example.com/article2</link>")
 	assert.Contains(t, xmlStr, "<description>Summary 2</description>")
+	assert.Contains(t, xmlStr, "<pubDate>Fri, 27 Oct 2023 10:00:00 +0000</pubDate>")
 }
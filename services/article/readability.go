@@ -0,0 +1,267 @@
+package articleService
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ErrNoContent is returned when no element of the page looks like an
+// article body.
+var ErrNoContent = errors.New("no article content found")
+
+// minParagraphLength is the length under which a paragraph does not count
+// towards the score of its ancestors.
+const minParagraphLength = 25
+
+var (
+	unlikelyCandidates = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|cover-wrap|disqus|extra|footer|gdpr|header|legends|menu|related|remark|replies|rss|shoutbox|sidebar|skyscraper|social|sponsor|supplemental|ad-break|agegate|pagination|pager|popup|yom-remote|newsletter|subscribe|share|promo`)
+	maybeCandidate     = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positiveNames      = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|pagination|post|text|blog|story`)
+	negativeNames      = regexp.MustCompile(`(?i)-ad-|hidden|^hid$| hid$| hid |^hid |banner|combx|comment|com-|contact|foot|footer|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget|byline|author|dateline|newsletter|subscribe`)
+)
+
+// removedTags never contain article text.
+var removedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true,
+	atom.Form: true, atom.Nav: true, atom.Header: true, atom.Footer: true,
+	atom.Aside: true, atom.Button: true, atom.Input: true, atom.Select: true,
+	atom.Textarea: true, atom.Svg: true, atom.Object: true, atom.Embed: true,
+	atom.Template: true, atom.Dialog: true,
+}
+
+// Extract returns the sanitized HTML of the main content of a page. When
+// selector is set the first element it matches is used, otherwise the
+// content is found with a readability-style scoring of the elements.
+func Extract(r io.Reader, pageURL string, selector string) (string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", fmt.Errorf("error parsing the article: %w", err)
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return "", err
+	}
+
+	var content *html.Node
+	if selector != "" {
+		sel, err := cascadia.Compile(selector)
+		if err != nil {
+			return "", fmt.Errorf("invalid content selector: %w", err)
+		}
+		content = sel.MatchFirst(doc)
+	} else {
+		content = readability(doc)
+	}
+	if content == nil {
+		return "", ErrNoContent
+	}
+
+	var buf bytes.Buffer
+	for c := content.FirstChild; c != nil; c = c.NextSibling {
+		if err := html.Render(&buf, c); err != nil {
+			return "", err
+		}
+	}
+	cleaned := Sanitize(buf.String(), base)
+	if strings.TrimSpace(cleaned) == "" {
+		return "", ErrNoContent
+	}
+	return cleaned, nil
+}
+
+// readability finds the element holding the article: paragraphs give points
+// to their parent and grandparent, weighted by the class and id of the
+// elements and penalized by their link density. The best scored element is
+// returned together with the siblings that look like part of the article.
+func readability(doc *html.Node) *html.Node {
+	prune(doc)
+
+	scores := map[*html.Node]float64{}
+	var candidates []*html.Node
+	addScore := func(n *html.Node, score float64) {
+		if n == nil || n.Type != html.ElementNode || n.DataAtom == atom.Html || n.DataAtom == atom.Body {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = initialScore(n)
+			candidates = append(candidates, n)
+		}
+		scores[n] += score
+	}
+
+	walk(doc, func(n *html.Node) {
+		switch n.DataAtom {
+		case atom.P, atom.Pre, atom.Td, atom.Blockquote:
+		default:
+			return
+		}
+		text := innerText(n)
+		if len(text) < minParagraphLength {
+			return
+		}
+		score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+		addScore(n.Parent, score)
+		if n.Parent != nil {
+			addScore(n.Parent.Parent, score/2)
+		}
+	})
+
+	var top *html.Node
+	for _, n := range candidates {
+		scores[n] *= 1 - linkDensity(n)
+		if top == nil || scores[n] > scores[top] {
+			top = n
+		}
+	}
+	if top == nil {
+		return nil
+	}
+
+	// Gather the siblings that belong to the article, e.g. the paragraphs
+	// split across several wrappers.
+	threshold := math.Max(10, scores[top]*0.2)
+	article := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	for sibling := top.Parent.FirstChild; sibling != nil; {
+		next := sibling.NextSibling
+		keep := sibling == top
+		if !keep && sibling.Type == html.ElementNode {
+			if score, ok := scores[sibling]; ok && score >= threshold {
+				keep = true
+			} else if sibling.DataAtom == atom.P {
+				text := innerText(sibling)
+				density := linkDensity(sibling)
+				keep = (len(text) > 80 && density < 0.25) || (len(text) > 0 && density == 0 && strings.HasSuffix(text, "."))
+			}
+		}
+		if keep {
+			sibling.Parent.RemoveChild(sibling)
+			article.AppendChild(sibling)
+		}
+		sibling = next
+	}
+	return article
+}
+
+// prune removes the elements that cannot be part of the article.
+func prune(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		switch {
+		case c.Type == html.CommentNode:
+			n.RemoveChild(c)
+		case c.Type == html.ElementNode && removedTags[c.DataAtom]:
+			n.RemoveChild(c)
+		case c.Type == html.ElementNode && isUnlikely(c):
+			n.RemoveChild(c)
+		default:
+			prune(c)
+		}
+		c = next
+	}
+}
+
+func isUnlikely(n *html.Node) bool {
+	if n.DataAtom == atom.Body || n.DataAtom == atom.A || n.DataAtom == atom.Article || n.DataAtom == atom.Main {
+		return false
+	}
+	names := attr(n, "class") + " " + attr(n, "id")
+	if attr(n, "role") == "complementary" || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	return unlikelyCandidates.MatchString(names) && !maybeCandidate.MatchString(names)
+}
+
+// initialScore favors the elements usually wrapping articles.
+func initialScore(n *html.Node) float64 {
+	score := classWeight(n)
+	switch n.DataAtom {
+	case atom.Article, atom.Main:
+		score += 10
+	case atom.Div:
+		score += 5
+	case atom.Pre, atom.Td, atom.Blockquote, atom.Section:
+		score += 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li, atom.Form:
+		score -= 3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score -= 5
+	}
+	return score
+}
+
+func classWeight(n *html.Node) float64 {
+	weight := 0.0
+	for _, name := range []string{attr(n, "class"), attr(n, "id")} {
+		if name == "" {
+			continue
+		}
+		if negativeNames.MatchString(name) {
+			weight -= 25
+		}
+		if positiveNames.MatchString(name) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+// linkDensity is the share of the text of n inside links.
+func linkDensity(n *html.Node) float64 {
+	length := len(innerText(n))
+	if length == 0 {
+		return 0
+	}
+	links := 0
+	walk(n, func(c *html.Node) {
+		if c.DataAtom == atom.A {
+			links += len(innerText(c))
+		}
+	})
+	return float64(links) / float64(length)
+}
+
+// walk calls fn for every element under n, n excluded.
+func walk(n *html.Node, fn func(*html.Node)) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			fn(c)
+		}
+		walk(c, fn)
+	}
+}
+
+// innerText returns the text under n with its whitespace collapsed.
+func innerText(n *html.Node) string {
+	var b strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+	}
+	collect(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package articleService
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testArticlePage = `<!DOCTYPE html>
+<html>
+<head><title>Hello</title><script>track()</script></head>
+<body>
+	<header class="site-header"><a href="/">Home</a> <a href="/news">News</a></header>
+	<nav class="menu"><ul><li><a href="/a">A</a></li><li><a href="/b">B</a></li></ul></nav>
+	<div class="layout">
+		<div class="sidebar">
+			<p>Subscribe to our newsletter to get the latest news, every week, for free.</p>
+		</div>
+		<div class="post-content">
+			<h2>A subtitle</h2>
+			<p>The first paragraph of the article is long enough to count, with a few commas, here and there.</p>
+			<p>The second paragraph keeps going, explaining <a href="/more">the details</a> of the story, at length.</p>
+			<figure><img data-src="/images/hello.png" alt="Hello"><figcaption>A picture</figcaption></figure>
+			<p onclick="steal()">A third paragraph, <em>with emphasis</em>, so the scoring has enough text to work with.</p>
+			<script>alert("no")</script>
+		</div>
+		<div class="related-posts">
+			<p><a href="/other">Another article you may like, with a title long enough to count</a></p>
+		</div>
+	</div>
+	<footer><p>Copyright, all rights reserved, since the beginning of times.</p></footer>
+</body>
+</html>`
+
+func TestExtract_Readability(t *testing.T) {
+	content, err := Extract(strings.NewReader(testArticlePage), "https://www.example.com/news/hello", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, content, "The first paragraph of the article")
+	assert.Contains(t, content, "A third paragraph, <em>with emphasis</em>")
+	assert.Contains(t, content, `<a href="https://www.example.com/more">the details</a>`)
+	assert.Contains(t, content, `<img alt="Hello" src="https://www.example.com/images/hello.png"/>`)
+	assert.NotContains(t, content, "newsletter")
+	assert.NotContains(t, content, "Another article")
+	assert.NotContains(t, content, "Copyright")
+	assert.NotContains(t, content, "script")
+	assert.NotContains(t, content, "onclick")
+	assert.NotContains(t, content, "class=")
+}
+
+func TestExtract_Selector(t *testing.T) {
+	content, err := Extract(strings.NewReader(testArticlePage), "https://www.example.com/news/hello", ".sidebar")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "<p>Subscribe to our newsletter to get the latest news, every week, for free.</p>", content)
+
+	_, err = Extract(strings.NewReader(testArticlePage), "https://www.example.com/news/hello", ".missing")
+	assert.ErrorIs(t, err, ErrNoContent)
+}
+
+func TestExtract_NoContent(t *testing.T) {
+	_, err := Extract(strings.NewReader(`<html><body><a href="/">Home</a></body></html>`), "https://www.example.com/", "")
+	assert.ErrorIs(t, err, ErrNoContent)
+}
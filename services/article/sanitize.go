@@ -0,0 +1,160 @@
+package articleService
+
+import (
+	"bytes"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedTags are kept by Sanitize with the listed attributes. Other
+// elements are replaced by their children, except the droppedTags.
+var allowedTags = map[atom.Atom][]string{
+	atom.P: nil, atom.Br: nil, atom.Hr: nil, atom.Div: nil, atom.Span: nil,
+	atom.H1: nil, atom.H2: nil, atom.H3: nil, atom.H4: nil, atom.H5: nil, atom.H6: nil,
+	atom.B: nil, atom.Strong: nil, atom.I: nil, atom.Em: nil, atom.U: nil, atom.S: nil,
+	atom.Sub: nil, atom.Sup: nil, atom.Small: nil, atom.Mark: nil, atom.Q: nil, atom.Cite: nil,
+	atom.Code: nil, atom.Pre: nil, atom.Kbd: nil, atom.Blockquote: nil,
+	atom.Ul: nil, atom.Ol: nil, atom.Li: nil, atom.Dl: nil, atom.Dt: nil, atom.Dd: nil,
+	atom.Figure: nil, atom.Figcaption: nil, atom.Picture: nil,
+	atom.Table: nil, atom.Thead: nil, atom.Tbody: nil, atom.Tfoot: nil, atom.Tr: nil,
+	atom.Caption: nil,
+	atom.Th:      {"colspan", "rowspan"},
+	atom.Td:      {"colspan", "rowspan"},
+	atom.A:       {"href", "title"},
+	atom.Img:     {"src", "alt", "title", "width", "height"},
+	atom.Time:    {"datetime"},
+	atom.Abbr:    {"title"},
+}
+
+// droppedTags are removed with their content.
+var droppedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true,
+	atom.Frame: true, atom.Frameset: true, atom.Object: true, atom.Embed: true,
+	atom.Applet: true, atom.Form: true, atom.Input: true, atom.Button: true,
+	atom.Select: true, atom.Textarea: true, atom.Svg: true, atom.Math: true,
+	atom.Template: true, atom.Link: true, atom.Meta: true, atom.Base: true,
+	atom.Title: true, atom.Head: true, atom.Source: true, atom.Audio: true,
+	atom.Video: true, atom.Canvas: true, atom.Dialog: true,
+}
+
+// urlAttrs hold links, resolved against the page URL.
+var urlAttrs = map[string]bool{"href": true, "src": true}
+
+// Sanitize keeps the harmless markup of an HTML fragment: scripts, styles,
+// forms and embeds are removed, unknown elements are unwrapped, attributes
+// are limited to a short list and relative links are resolved against base.
+func Sanitize(fragment string, base *url.URL) string {
+	root := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), root)
+	if err != nil {
+		return ""
+	}
+	for _, n := range nodes {
+		root.AppendChild(n)
+	}
+	sanitize(root, base)
+
+	var buf bytes.Buffer
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		html.Render(&buf, c)
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+func sanitize(n *html.Node, base *url.URL) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		switch c.Type {
+		case html.TextNode:
+		case html.ElementNode:
+			allowed, ok := allowedTags[c.DataAtom]
+			switch {
+			case droppedTags[c.DataAtom]:
+				n.RemoveChild(c)
+			case !ok:
+				// Unwrap the element, its children are sanitized in turn
+				first := c.FirstChild
+				for gc := c.FirstChild; gc != nil; {
+					gcNext := gc.NextSibling
+					c.RemoveChild(gc)
+					n.InsertBefore(gc, c)
+					gc = gcNext
+				}
+				n.RemoveChild(c)
+				if first != nil {
+					next = first
+				}
+			default:
+				c.Attr = sanitizeAttrs(c, allowed, base)
+				if c.DataAtom == atom.Img && attr(c, "src") == "" {
+					n.RemoveChild(c)
+					break
+				}
+				sanitize(c, base)
+			}
+		default:
+			n.RemoveChild(c)
+		}
+		c = next
+	}
+}
+
+func sanitizeAttrs(n *html.Node, allowed []string, base *url.URL) []html.Attribute {
+	// Lazy loaded images keep their source in a data attribute
+	if n.DataAtom == atom.Img {
+		for _, key := range []string{"data-src", "data-original", "data-lazy-src"} {
+			if src := attr(n, key); src != "" {
+				setAttr(n, "src", src)
+				break
+			}
+		}
+	}
+
+	attrs := []html.Attribute{}
+	for _, a := range n.Attr {
+		if a.Namespace != "" || !slices.Contains(allowed, a.Key) {
+			continue
+		}
+		if urlAttrs[a.Key] {
+			resolved, ok := safeURL(a.Val, base)
+			if !ok {
+				continue
+			}
+			a.Val = resolved
+		}
+		attrs = append(attrs, a)
+	}
+	return attrs
+}
+
+// safeURL resolves a link against base and rejects the schemes that could
+// run code in a reader.
+func safeURL(link string, base *url.URL) (string, bool) {
+	ref, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return "", false
+	}
+	if base != nil {
+		ref = base.ResolveReference(ref)
+	}
+	switch strings.ToLower(ref.Scheme) {
+	case "http", "https", "mailto", "":
+		return ref.String(), true
+	default:
+		return "", false
+	}
+}
+
+func setAttr(n *html.Node, key, value string) {
+	for i := range n.Attr {
+		if n.Attr[i].Key == key {
+			n.Attr[i].Val = value
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: value})
+}
@@ -0,0 +1,32 @@
+package articleService
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	base, _ := url.Parse("https://www.example.com/news/")
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"keeps simple markup", `<p>Hello <strong>world</strong></p>`, `<p>Hello <strong>world</strong></p>`},
+		{"drops scripts with their content", `<p>Hi</p><script>alert(1)</script><style>p{}</style>`, `<p>Hi</p>`},
+		{"unwraps unknown elements", `<section><custom-tag><p>Text</p></custom-tag></section>`, `<p>Text</p>`},
+		{"drops attributes", `<p class="x" style="color:red" onclick="x()">Text</p>`, `<p>Text</p>`},
+		{"resolves links", `<a href="../about" target="_blank">About</a>`, `<a href="https://www.example.com/about">About</a>`},
+		{"drops javascript links", `<a href="javascript:alert(1)">Click</a>`, `<a>Click</a>`},
+		{"lazy images", `<img src="data:image/gif;base64,R0l" data-src="/a.png">`, `<img src="https://www.example.com/a.png"/>`},
+		{"drops images without source", `<p><img src="javascript:x"></p>`, `<p></p>`},
+		{"drops comments", `<p>a<!-- hidden -->b</p>`, `<p>ab</p>`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, Sanitize(test.input, base))
+		})
+	}
+}
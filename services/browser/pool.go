@@ -0,0 +1,283 @@
+package browserService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// ErrPoolExhausted is returned when no tab became free within WaitTimeout.
+var ErrPoolExhausted = errors.New("browser pool exhausted")
+
+// waitKey marks the contexts of the callers that wait for a tab until
+// their context is done, see WithoutWaitTimeout.
+type waitKey struct{}
+
+// WithoutWaitTimeout returns a context whose Acquire waits for a free tab
+// until ctx is done rather than for WaitTimeout, for background work that
+// can queue behind the other tabs.
+func WithoutWaitTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, waitKey{}, true)
+}
+
+// Options configures a Pool.
+type Options struct {
+	Size           int           // number of tabs used concurrently
+	MaxUses        int           // navigations before a tab is recycled, 0 means never
+	WaitTimeout    time.Duration // how long Acquire waits for a free tab
+	HealthInterval time.Duration // how often idle tabs are checked, 0 disables the checks
+}
+
+// DefaultOptions are used for the zero values of Options.
+var DefaultOptions = Options{
+	Size:           2,
+	MaxUses:        50,
+	WaitTimeout:    30 * time.Second,
+	HealthInterval: time.Minute,
+}
+
+// Tab is a browser tab lent by a Pool.
+type Tab struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	uses   int
+}
+
+// Context derives a context running in the tab from ctx: chromedp actions
+// run in the tab and stop when ctx is done. Cancel it once finished.
+func (t *Tab) Context(ctx context.Context) (context.Context, context.CancelFunc) {
+	var runCtx context.Context
+	var cancel context.CancelFunc
+	if deadline, ok := ctx.Deadline(); ok {
+		runCtx, cancel = context.WithDeadline(t.ctx, deadline)
+	} else {
+		runCtx, cancel = context.WithCancel(t.ctx)
+	}
+	stop := context.AfterFunc(ctx, cancel)
+	return runCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// Pool lends a bounded number of tabs of a shared headless browser. Tabs are
+// created lazily, recycled after MaxUses navigations or a failure, and
+// checked in the background while idle.
+type Pool struct {
+	opts    Options
+	permits chan struct{} // one per tab in use
+	idle    chan *Tab
+
+	mu            sync.Mutex
+	allocCtx      context.Context
+	cancelAlloc   context.CancelFunc
+	browserCtx    context.Context
+	cancelBrowser context.CancelFunc
+
+	// newTab and check are replaced in tests
+	newTab func() (*Tab, error)
+	check  func(ctx context.Context) error
+
+	done chan struct{}
+}
+
+func NewPool(opts Options) *Pool {
+	if opts.Size <= 0 {
+		opts.Size = DefaultOptions.Size
+	}
+	if opts.WaitTimeout <= 0 {
+		opts.WaitTimeout = DefaultOptions.WaitTimeout
+	}
+	p := &Pool{
+		opts:    opts,
+		permits: make(chan struct{}, opts.Size),
+		idle:    make(chan *Tab, opts.Size),
+		done:    make(chan struct{}),
+	}
+	p.allocCtx, p.cancelAlloc = chromedp.NewExecAllocator(context.Background(), chromedp.DefaultExecAllocatorOptions[:]...)
+	p.newTab = p.newChromeTab
+	p.check = func(ctx context.Context) error {
+		var result int
+		return chromedp.Run(ctx, chromedp.Evaluate(`1`, &result))
+	}
+	if opts.HealthInterval > 0 {
+		go p.healthChecks()
+	}
+	return p
+}
+
+// Acquire lends a tab, waiting up to WaitTimeout when every tab is in use,
+// or until ctx is done for a context of WithoutWaitTimeout. The tab must be
+// given back with Release.
+func (p *Pool) Acquire(ctx context.Context) (*Tab, error) {
+	var expired <-chan time.Time
+	if wait, _ := ctx.Value(waitKey{}).(bool); !wait {
+		timer := time.NewTimer(p.opts.WaitTimeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case p.permits <- struct{}{}:
+	case <-expired:
+		return nil, fmt.Errorf("%w after %s", ErrPoolExhausted, p.opts.WaitTimeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	for {
+		select {
+		case tab := <-p.idle:
+			if tab.ctx.Err() != nil {
+				// The tab or its browser crashed while idle
+				tab.cancel()
+				continue
+			}
+			return tab, nil
+		default:
+		}
+		tab, err := p.newTab()
+		if err != nil {
+			<-p.permits
+			return nil, err
+		}
+		return tab, nil
+	}
+}
+
+// Release gives a tab back. err is the result of the work done in the tab,
+// a failed tab is closed instead of being reused.
+func (p *Pool) Release(tab *Tab, err error) {
+	defer func() { <-p.permits }()
+	tab.uses++
+	switch {
+	case err != nil:
+		log.Printf("Recycling browser tab after an error: %v", err)
+		tab.cancel()
+	case tab.ctx.Err() != nil:
+		log.Println("Recycling crashed browser tab.")
+		tab.cancel()
+	case p.opts.MaxUses > 0 && tab.uses >= p.opts.MaxUses:
+		log.Printf("Recycling browser tab after %d navigations.", tab.uses)
+		tab.cancel()
+	default:
+		p.idle <- tab
+	}
+}
+
+// Run runs fn in a tab of the pool, in a context derived from ctx by
+// Tab.Context, and releases the tab with the error of fn so a tab that
+// failed is recycled.
+func (p *Pool) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	tab, err := p.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	tabCtx, cancel := tab.Context(ctx)
+	defer cancel()
+	err = fn(tabCtx)
+	p.Release(tab, err)
+	return err
+}
+
+// Close closes every tab and the browser.
+func (p *Pool) Close() {
+	close(p.done)
+	for {
+		select {
+		case tab := <-p.idle:
+			tab.cancel()
+		default:
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if p.cancelBrowser != nil {
+				p.cancelBrowser()
+			}
+			p.cancelAlloc()
+			return
+		}
+	}
+}
+
+// browser returns the context of the shared browser, starting it again if
+// it is not running.
+func (p *Pool) browser() (context.Context, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.browserCtx != nil && p.browserCtx.Err() == nil {
+		return p.browserCtx, nil
+	}
+	if p.browserCtx != nil {
+		log.Println("Browser is gone, starting a new one.")
+	}
+	ctx, cancel := chromedp.NewContext(p.allocCtx)
+	if err := chromedp.Run(ctx); err != nil {
+		cancel()
+		return nil, fmt.Errorf("error starting the browser: %w", err)
+	}
+	p.browserCtx, p.cancelBrowser = ctx, cancel
+	return ctx, nil
+}
+
+func (p *Pool) newChromeTab() (*Tab, error) {
+	browserCtx, err := p.browser()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := chromedp.NewContext(browserCtx)
+	if err := chromedp.Run(ctx); err != nil {
+		cancel()
+		return nil, fmt.Errorf("error opening a browser tab: %w", err)
+	}
+	return &Tab{ctx: ctx, cancel: cancel}, nil
+}
+
+// healthChecks periodically checks the idle tabs and closes the ones that
+// do not respond.
+func (p *Pool) healthChecks() {
+	ticker := time.NewTicker(p.opts.HealthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.checkIdle()
+		}
+	}
+}
+
+func (p *Pool) checkIdle() {
+	for i := 0; i < p.opts.Size; i++ {
+		// Borrow a permit so the check never exceeds the pool size
+		select {
+		case p.permits <- struct{}{}:
+		default:
+			return
+		}
+		var tab *Tab
+		select {
+		case tab = <-p.idle:
+		default:
+			<-p.permits
+			return
+		}
+		ctx, cancel := tab.Context(context.Background())
+		ctx, cancelTimeout := context.WithTimeout(ctx, 10*time.Second)
+		err := p.check(ctx)
+		cancelTimeout()
+		cancel()
+		if err != nil {
+			log.Printf("Browser tab failed its health check: %v", err)
+			tab.cancel()
+			<-p.permits
+			continue
+		}
+		p.idle <- tab
+		<-p.permits
+	}
+}
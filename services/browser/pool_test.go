@@ -0,0 +1,163 @@
+package browserService
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestPool returns a pool whose tabs are plain contexts.
+func newTestPool(opts Options) (*Pool, *int) {
+	pool := NewPool(opts)
+	created := 0
+	pool.newTab = func() (*Tab, error) {
+		created++
+		ctx, cancel := context.WithCancel(context.Background())
+		return &Tab{ctx: ctx, cancel: cancel}, nil
+	}
+	pool.check = func(ctx context.Context) error { return nil }
+	return pool, &created
+}
+
+func TestPool_ReusesTabs(t *testing.T) {
+	pool, created := newTestPool(Options{Size: 2})
+	defer pool.Close()
+
+	tab, err := pool.Acquire(context.Background())
+	assert.NoError(t, err)
+	pool.Release(tab, nil)
+	again, err := pool.Acquire(context.Background())
+	assert.NoError(t, err)
+	assert.Same(t, tab, again)
+	assert.Equal(t, 1, *created)
+	pool.Release(again, nil)
+}
+
+func TestPool_WaitTimeout(t *testing.T) {
+	pool, _ := newTestPool(Options{Size: 1, WaitTimeout: 20 * time.Millisecond})
+	defer pool.Close()
+
+	tab, err := pool.Acquire(context.Background())
+	assert.NoError(t, err)
+	_, err = pool.Acquire(context.Background())
+	assert.ErrorIs(t, err, ErrPoolExhausted)
+
+	// A waiting caller gets the tab as soon as it is released
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		pool.Release(tab, nil)
+	}()
+	again, err := pool.Acquire(context.Background())
+	assert.NoError(t, err)
+	assert.Same(t, tab, again)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = pool.Acquire(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Background work waits until its own deadline
+	go func() {
+		time.Sleep(40 * time.Millisecond)
+		pool.Release(again, nil)
+	}()
+	ctx, cancel = context.WithTimeout(WithoutWaitTimeout(context.Background()), time.Second)
+	defer cancel()
+	tab, err = pool.Acquire(ctx)
+	assert.NoError(t, err)
+	assert.Same(t, again, tab)
+	ctx, cancel = context.WithTimeout(WithoutWaitTimeout(context.Background()), 40*time.Millisecond)
+	defer cancel()
+	_, err = pool.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestPool_Recycling(t *testing.T) {
+	pool, created := newTestPool(Options{Size: 1, MaxUses: 2})
+	defer pool.Close()
+
+	first, _ := pool.Acquire(context.Background())
+	pool.Release(first, nil)
+	first, _ = pool.Acquire(context.Background())
+	pool.Release(first, nil)
+	assert.Error(t, first.ctx.Err(), "recycled after MaxUses navigations")
+
+	second, _ := pool.Acquire(context.Background())
+	assert.NotSame(t, first, second)
+	pool.Release(second, errors.New("navigation failed"))
+	assert.Error(t, second.ctx.Err(), "recycled after an error")
+
+	third, _ := pool.Acquire(context.Background())
+	third.cancel() // the tab crashes while in use
+	pool.Release(third, nil)
+	fourth, _ := pool.Acquire(context.Background())
+	assert.NotSame(t, third, fourth)
+	assert.Equal(t, 4, *created)
+	pool.Release(fourth, nil)
+}
+
+func TestPool_HealthCheck(t *testing.T) {
+	pool, created := newTestPool(Options{Size: 2})
+	defer pool.Close()
+
+	first, _ := pool.Acquire(context.Background())
+	second, _ := pool.Acquire(context.Background())
+	pool.Release(first, nil)
+	pool.Release(second, nil)
+
+	pool.check = func(ctx context.Context) error {
+		if tab, ok := ctx.Value(tabKey{}).(*Tab); ok && tab == first {
+			return errors.New("unresponsive")
+		}
+		return nil
+	}
+	first.ctx = context.WithValue(first.ctx, tabKey{}, first)
+	second.ctx = context.WithValue(second.ctx, tabKey{}, second)
+	pool.checkIdle()
+
+	assert.Len(t, pool.idle, 1)
+	tab, _ := pool.Acquire(context.Background())
+	assert.Same(t, second, tab)
+	assert.Equal(t, 2, *created)
+	pool.Release(tab, nil)
+}
+
+type tabKey struct{}
+
+func TestTab_Context(t *testing.T) {
+	tabCtx, cancelTab := context.WithCancel(context.WithValue(context.Background(), tabKey{}, "tab"))
+	defer cancelTab()
+	tab := &Tab{ctx: tabCtx, cancel: cancelTab}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	runCtx, stop := tab.Context(ctx)
+	assert.Equal(t, "tab", runCtx.Value(tabKey{}))
+	deadline, ok := runCtx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), deadline, time.Minute)
+
+	cancel()
+	assert.Eventually(t, func() bool { return runCtx.Err() != nil }, time.Second, time.Millisecond)
+	assert.NoError(t, tabCtx.Err(), "the tab outlives the run")
+	stop()
+}
+
+func TestPool_Run(t *testing.T) {
+	pool, created := newTestPool(Options{Size: 1})
+	defer pool.Close()
+
+	assert.NoError(t, pool.Run(context.Background(), func(ctx context.Context) error { return nil }))
+
+	// A failed run closes its tab instead of giving it back
+	err := pool.Run(context.Background(), func(ctx context.Context) error {
+		return errors.New("navigation failed")
+	})
+	assert.EqualError(t, err, "navigation failed")
+	assert.Equal(t, 1, *created, "the first run reused the tab")
+
+	assert.NoError(t, pool.Run(context.Background(), func(ctx context.Context) error { return nil }))
+	assert.Equal(t, 2, *created, "the failed tab was replaced")
+}
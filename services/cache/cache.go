@@ -2,39 +2,114 @@ package cacheService
 
 import (
 	"sync"
+	"time"
 )
 
 // Cacher defines the interface for a cache service.
 type Cacher interface {
+	// Get returns the value of a key that has not expired.
 	Get(key string) (string, bool)
-	Set(key, value string)
+	// Lookup returns the entry of a key even if it has expired, so callers
+	// can serve stale content while refreshing it.
+	Lookup(key string) (Entry, bool)
+	// Set stores a value, it expires after ttl when one is given.
+	Set(key, value string, ttl ...time.Duration)
 	Delete(key string)
 }
 
-// memoryCache implements the Cacher interface.
+// StaleRetention is how long an expired entry is still returned by Lookup,
+// for the callers serving stale content, before it is evicted.
+var StaleRetention = 7 * 24 * time.Hour
+
+// sweepInterval is the minimum time between two sweeps of the evicted
+// entries.
+const sweepInterval = time.Hour
+
+// Entry is a cached value with its expiry time.
+type Entry struct {
+	Value   string    `json:"value"`
+	Expires time.Time `json:"expires,omitempty"` // zero means never
+}
+
+// Expired reports whether the entry is past its TTL.
+func (e Entry) Expired() bool {
+	return !e.Expires.IsZero() && time.Now().After(e.Expires)
+}
+
+// evicted reports whether the entry expired more than StaleRetention ago
+// and can be dropped.
+func (e Entry) evicted(now time.Time) bool {
+	return !e.Expires.IsZero() && now.Sub(e.Expires) > StaleRetention
+}
+
+// newEntry builds the entry of a value stored with an optional ttl.
+func newEntry(value string, ttl []time.Duration) Entry {
+	entry := Entry{Value: value}
+	if len(ttl) > 0 && ttl[0] > 0 {
+		entry.Expires = time.Now().Add(ttl[0])
+	}
+	return entry
+}
+
+// memoryCache implements the Cacher interface. Evicted entries are dropped
+// when looked up and by a sweep of the whole cache run by Set at most once
+// per sweepInterval.
 type memoryCache struct {
-	cache map[string]string
-	mu    sync.RWMutex
+	cache     map[string]Entry
+	mu        sync.RWMutex
+	nextSweep time.Time
 }
 
 // NewMemoryCache creates instance of memoryCache
 func NewMemoryCache() *memoryCache {
 	return &memoryCache{
-		cache: make(map[string]string),
+		cache: make(map[string]Entry),
 	}
 }
 
 func (c *memoryCache) Get(key string) (string, bool) {
+	entry, ok := c.Lookup(key)
+	if !ok || entry.Expired() {
+		return "", false
+	}
+	return entry.Value, true
+}
+
+func (c *memoryCache) Lookup(key string) (Entry, bool) {
 	c.mu.RLock()
-	defer c.mu.RUnlock()
-	value, ok := c.cache[key]
-	return value, ok
+	entry, ok := c.cache[key]
+	c.mu.RUnlock()
+	if ok && entry.evicted(time.Now()) {
+		c.mu.Lock()
+		// The entry may have been replaced in the meantime
+		if entry, ok := c.cache[key]; ok && entry.evicted(time.Now()) {
+			delete(c.cache, key)
+		}
+		c.mu.Unlock()
+		return Entry{}, false
+	}
+	return entry, ok
 }
 
-func (c *memoryCache) Set(key, value string) {
+func (c *memoryCache) Set(key, value string, ttl ...time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.cache[key] = value
+	c.cache[key] = newEntry(value, ttl)
+	c.sweep(time.Now())
+}
+
+// sweep drops the evicted entries, at most once per sweepInterval. c.mu
+// must be held.
+func (c *memoryCache) sweep(now time.Time) {
+	if now.Before(c.nextSweep) {
+		return
+	}
+	c.nextSweep = now.Add(sweepInterval)
+	for key, entry := range c.cache {
+		if entry.evicted(now) {
+			delete(c.cache, key)
+		}
+	}
 }
 
 func (c *memoryCache) Delete(key string) {
@@ -0,0 +1,50 @@
+package cacheService
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryCache_TTL(t *testing.T) {
+	cache := NewMemoryCache()
+	cache.Set("forever", "value")
+	cache.Set("fresh", "value", time.Hour)
+	cache.Set("expired", "stale", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	_, ok := cache.Get("forever")
+	assert.True(t, ok)
+	_, ok = cache.Get("fresh")
+	assert.True(t, ok)
+	_, ok = cache.Get("expired")
+	assert.False(t, ok)
+
+	entry, ok := cache.Lookup("expired")
+	assert.True(t, ok)
+	assert.True(t, entry.Expired())
+	assert.Equal(t, "stale", entry.Value)
+	entry, _ = cache.Lookup("forever")
+	assert.False(t, entry.Expired())
+}
+
+func TestMemoryCache_Eviction(t *testing.T) {
+	defer func(retention time.Duration) { StaleRetention = retention }(StaleRetention)
+	StaleRetention = time.Millisecond
+	cache := NewMemoryCache()
+	cache.Set("forever", "value")
+	cache.Set("looked-up", "stale", time.Nanosecond)
+	cache.Set("swept", "stale", time.Nanosecond)
+	time.Sleep(5 * time.Millisecond)
+
+	_, ok := cache.Lookup("looked-up")
+	assert.False(t, ok)
+	assert.NotContains(t, cache.cache, "looked-up")
+
+	cache.nextSweep = time.Time{}
+	cache.Set("fresh", "value", time.Hour)
+	assert.NotContains(t, cache.cache, "swept")
+	assert.Contains(t, cache.cache, "forever")
+	assert.Contains(t, cache.cache, "fresh")
+}
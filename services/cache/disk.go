@@ -0,0 +1,232 @@
+package cacheService
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	diskEntryExt   = ".json"
+	diskTempPrefix = ".tmp-"
+	diskCorruptExt = ".corrupt"
+)
+
+// diskEntry is the content of one cache file. The checksum detects files
+// truncated or damaged outside of the atomic write path.
+type diskEntry struct {
+	Key      string    `json:"key"`
+	Value    string    `json:"value"`
+	Expires  time.Time `json:"expires,omitempty"`
+	Checksum uint32    `json:"checksum"`
+}
+
+// diskCache implements the Cacher interface on top of a directory, one file
+// per key, so the cache survives restarts. Only the expiry times are kept in
+// memory, values are read from disk when looked up. Evicted entries are
+// removed when loaded or looked up, and by a sweep run by Set at most once
+// per sweepInterval.
+type diskCache struct {
+	dir       string
+	expires   map[string]time.Time
+	mu        sync.RWMutex
+	nextSweep time.Time
+}
+
+// NewDiskCache opens, or creates, a disk cache in dir. Corrupted entries are
+// moved aside and leftovers of interrupted writes are removed.
+func NewDiskCache(dir string) (*diskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating cache directory: %w", err)
+	}
+	c := &diskCache{
+		dir:     dir,
+		expires: make(map[string]time.Time),
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *diskCache) load() error {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("error reading cache directory: %w", err)
+	}
+	now := time.Now()
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(c.dir, name)
+		switch {
+		case entry.IsDir():
+			continue
+		case strings.HasPrefix(name, diskTempPrefix):
+			os.Remove(path)
+		case strings.HasSuffix(name, diskEntryExt):
+			key, cached, err := readDiskEntry(path)
+			if err != nil {
+				log.Printf("Moving corrupted cache entry %s aside: %v", name, err)
+				os.Rename(path, path+diskCorruptExt)
+				continue
+			}
+			if cached.evicted(now) {
+				os.Remove(path)
+				continue
+			}
+			c.expires[key] = cached.Expires
+		}
+	}
+	log.Printf("Loaded %d entries from the %s cache.", len(c.expires), c.dir)
+	return nil
+}
+
+func readDiskEntry(path string) (string, Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", Entry{}, err
+	}
+	var entry diskEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return "", Entry{}, err
+	}
+	if crc32.ChecksumIEEE([]byte(entry.Value)) != entry.Checksum {
+		return "", Entry{}, fmt.Errorf("checksum mismatch")
+	}
+	if filepath.Base(path) != diskFileName(entry.Key) {
+		return "", Entry{}, fmt.Errorf("key %q does not match the file name", entry.Key)
+	}
+	return entry.Key, Entry{Value: entry.Value, Expires: entry.Expires}, nil
+}
+
+func diskFileName(key string) string {
+	return url.PathEscape(key) + diskEntryExt
+}
+
+// write stores the entry in a temporary file and renames it over the
+// previous one, so a crash never leaves a half written entry behind.
+func (c *diskCache) write(key string, entry Entry) error {
+	data, err := json.Marshal(diskEntry{
+		Key:      key,
+		Value:    entry.Value,
+		Expires:  entry.Expires,
+		Checksum: crc32.ChecksumIEEE([]byte(entry.Value)),
+	})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.dir, diskTempPrefix)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(c.dir, diskFileName(key)))
+}
+
+func (c *diskCache) Get(key string) (string, bool) {
+	entry, ok := c.Lookup(key)
+	if !ok || entry.Expired() {
+		return "", false
+	}
+	return entry.Value, true
+}
+
+func (c *diskCache) Lookup(key string) (Entry, bool) {
+	c.mu.RLock()
+	expires, ok := c.expires[key]
+	if !ok {
+		c.mu.RUnlock()
+		return Entry{}, false
+	}
+	if (Entry{Expires: expires}).evicted(time.Now()) {
+		c.mu.RUnlock()
+		c.evict(key, expires, "")
+		return Entry{}, false
+	}
+	// Writes rename a complete file over the previous one, the file is
+	// never read half written.
+	path := filepath.Join(c.dir, diskFileName(key))
+	_, entry, err := readDiskEntry(path)
+	c.mu.RUnlock()
+	if err != nil {
+		log.Printf("Moving unreadable cache entry %s aside: %v", filepath.Base(path), err)
+		c.evict(key, expires, diskCorruptExt)
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// evict drops the entry of key, unless it was replaced since its expiry
+// time was read. Its file is removed, or renamed with suffix when one is
+// given.
+func (c *diskCache) evict(key string, expires time.Time, suffix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if current, ok := c.expires[key]; !ok || !current.Equal(expires) {
+		return
+	}
+	if suffix != "" {
+		path := filepath.Join(c.dir, diskFileName(key))
+		os.Rename(path, path+suffix)
+	}
+	c.remove(key)
+}
+
+func (c *diskCache) Set(key, value string, ttl ...time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := newEntry(value, ttl)
+	if err := c.write(key, entry); err != nil {
+		log.Printf("Error writing `%s` to the disk cache: %v", key, err)
+		return
+	}
+	c.expires[key] = entry.Expires
+	c.sweep(time.Now())
+}
+
+// sweep removes the evicted entries, at most once per sweepInterval. c.mu
+// must be held.
+func (c *diskCache) sweep(now time.Time) {
+	if now.Before(c.nextSweep) {
+		return
+	}
+	c.nextSweep = now.Add(sweepInterval)
+	for key, expires := range c.expires {
+		if (Entry{Expires: expires}).evicted(now) {
+			c.remove(key)
+		}
+	}
+}
+
+func (c *diskCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(key)
+}
+
+// remove drops the entry of key and its file. c.mu must be held.
+func (c *diskCache) remove(key string) {
+	delete(c.expires, key)
+	err := os.Remove(filepath.Join(c.dir, diskFileName(key)))
+	if err != nil && !os.IsNotExist(err) {
+		log.Printf("Error deleting `%s` from the disk cache: %v", key, err)
+	}
+}
@@ -0,0 +1,132 @@
+package cacheService
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiskCache_SurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewDiskCache(dir)
+	assert.NoError(t, err)
+	cache.Set("rss-theverge", "<rss/>")
+	cache.Set("rss/aws", "<rss>aws</rss>")
+	cache.Set("rss-theverge", "<rss>updated</rss>")
+	cache.Set("rss-nodeweekly", "<rss/>")
+	cache.Delete("rss-nodeweekly")
+	cache.Delete("missing")
+
+	reopened, err := NewDiskCache(dir)
+	assert.NoError(t, err)
+	value, ok := reopened.Get("rss-theverge")
+	assert.True(t, ok)
+	assert.Equal(t, "<rss>updated</rss>", value)
+	value, ok = reopened.Get("rss/aws")
+	assert.True(t, ok)
+	assert.Equal(t, "<rss>aws</rss>", value)
+	_, ok = reopened.Get("rss-nodeweekly")
+	assert.False(t, ok)
+}
+
+func TestDiskCache_CorruptionRecovery(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewDiskCache(dir)
+	assert.NoError(t, err)
+	cache.Set("good", "value")
+	cache.Set("truncated", "value")
+	cache.Set("tampered", "value")
+
+	path := filepath.Join(dir, diskFileName("truncated"))
+	data, _ := os.ReadFile(path)
+	assert.NoError(t, os.WriteFile(path, data[:len(data)/2], 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, diskFileName("tampered")), []byte(`{"key":"tampered","value":"other","checksum":1}`), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, diskTempPrefix+"123"), []byte("partial"), 0o644))
+
+	reopened, err := NewDiskCache(dir)
+	assert.NoError(t, err)
+	_, ok := reopened.Get("good")
+	assert.True(t, ok)
+	_, ok = reopened.Get("truncated")
+	assert.False(t, ok)
+	_, ok = reopened.Get("tampered")
+	assert.False(t, ok)
+
+	assert.FileExists(t, path+diskCorruptExt)
+	assert.NoFileExists(t, filepath.Join(dir, diskTempPrefix+"123"))
+
+	// The key can be written again after the recovery
+	reopened.Set("truncated", "fresh")
+	reopened, err = NewDiskCache(dir)
+	assert.NoError(t, err)
+	value, _ := reopened.Get("truncated")
+	assert.Equal(t, "fresh", value)
+}
+
+func TestDiskCache_TTL(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewDiskCache(dir)
+	assert.NoError(t, err)
+	cache.Set("fresh", "value", time.Hour)
+	cache.Set("expired", "stale", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	reopened, err := NewDiskCache(dir)
+	assert.NoError(t, err)
+	_, ok := reopened.Get("fresh")
+	assert.True(t, ok)
+	_, ok = reopened.Get("expired")
+	assert.False(t, ok)
+	entry, ok := reopened.Lookup("expired")
+	assert.True(t, ok)
+	assert.True(t, entry.Expired())
+}
+
+func TestDiskCache_Eviction(t *testing.T) {
+	defer func(retention time.Duration) { StaleRetention = retention }(StaleRetention)
+	StaleRetention = time.Millisecond
+	dir := t.TempDir()
+	cache, err := NewDiskCache(dir)
+	assert.NoError(t, err)
+	cache.Set("forever", "value")
+	cache.Set("looked-up", "stale", time.Nanosecond)
+	cache.Set("swept", "stale", time.Nanosecond)
+	cache.Set("loaded", "stale", time.Nanosecond)
+	time.Sleep(5 * time.Millisecond)
+
+	_, ok := cache.Lookup("looked-up")
+	assert.False(t, ok)
+	assert.NoFileExists(t, filepath.Join(dir, diskFileName("looked-up")))
+
+	cache.nextSweep = time.Time{}
+	cache.Set("fresh", "value", time.Hour)
+	assert.NotContains(t, cache.expires, "swept")
+	assert.NoFileExists(t, filepath.Join(dir, diskFileName("swept")))
+
+	// Only the expiry times are kept in memory
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, diskFileName("loaded")), []byte("{}"), 0o644))
+	StaleRetention = time.Hour
+	_, ok = cache.Lookup("loaded")
+	assert.False(t, ok, "the value is read from disk")
+	StaleRetention = time.Millisecond
+
+	cache.Set("loaded", "stale", time.Nanosecond)
+	time.Sleep(5 * time.Millisecond)
+	reopened, err := NewDiskCache(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"forever", "fresh"}, sortedKeys(reopened.expires))
+	assert.NoFileExists(t, filepath.Join(dir, diskFileName("loaded")))
+}
+
+func sortedKeys(m map[string]time.Time) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -4,21 +4,95 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math/rand"
 	"rss-generator/providers"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/robfig/cron/v3"
 )
 
+// DefaultSchedule is used for the providers that do not configure their own.
+var DefaultSchedule = providers.Schedule{
+	Cron:    "0 0 0 * * *", // Run every day at 00:00
+	Timeout: providers.Duration(10 * time.Minute),
+}
+
+// JobStatus describes a scheduled job.
+type JobStatus struct {
+	ID        string             `json:"id"`
+	Name      string             `json:"name"`
+	Schedule  providers.Schedule `json:"schedule"`
+	NextRun   time.Time          `json:"nextRun"`
+	LastRun   time.Time          `json:"lastRun"`
+	LastError string             `json:"lastError,omitempty"`
+	Running   bool               `json:"running"`
+}
+
+// job refreshes one provider. It is bound to its scraper when registered.
+type job struct {
+	id       string
+	name     string
+	schedule providers.Schedule
+	scraper  providers.Scraper
+	entryID  cron.EntryID
+
+	mu        sync.Mutex
+	lastRun   time.Time
+	lastError error
+	running   bool
+}
+
+// Run implements cron.Job.
+func (j *job) Run() {
+	if j.schedule.Jitter > 0 {
+		time.Sleep(time.Duration(rand.Int63n(int64(j.schedule.Jitter))))
+	}
+	j.run()
+}
+
+func (j *job) run() error {
+	j.mu.Lock()
+	j.running = true
+	j.mu.Unlock()
+
+	log.Printf("Running %s job...", j.name)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(j.schedule.Timeout))
+	defer cancel()
+	_, err := j.scraper.Scrape(ctx, "true")
+	if err != nil {
+		log.Printf("Error running %s job: %v", j.name, err)
+	} else {
+		log.Printf("%s job completed at %s", j.name, time.Now().Format(time.DateTime))
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.running = false
+	j.lastRun = time.Now()
+	j.lastError = err
+	return err
+}
+
 type CronService struct {
-	cron    *cron.Cron
-	scraper providers.Scraper
+	cron     *cron.Cron
+	defaults providers.Schedule
+	mu       sync.RWMutex
+	jobs     map[string]*job
 }
 
-func NewCronService(scraper providers.Scraper) *CronService {
+func NewCronService(defaults providers.Schedule) *CronService {
+	if defaults.Cron == "" {
+		defaults.Cron = DefaultSchedule.Cron
+	}
+	if defaults.Timeout == 0 {
+		defaults.Timeout = DefaultSchedule.Timeout
+	}
 	return &CronService{
-		cron:    cron.New(cron.WithSeconds()),
-		scraper: scraper,
+		cron:     cron.New(cron.WithSeconds()),
+		defaults: defaults,
+		jobs:     make(map[string]*job),
 	}
 }
 
@@ -33,62 +107,145 @@ func (s *CronService) Stop() {
 	log.Println("Cron service stopped.")
 }
 
-func (s *CronService) SetScraper(scraper providers.Scraper) {
-	s.scraper = scraper
+// schedule fills the empty values of the provider schedule with the defaults.
+func (s *CronService) schedule(schedule providers.Schedule) providers.Schedule {
+	if schedule.Cron == "" {
+		schedule.Cron = s.defaults.Cron
+	}
+	if schedule.Timeout == 0 {
+		schedule.Timeout = s.defaults.Timeout
+	}
+	if schedule.Jitter == 0 {
+		schedule.Jitter = s.defaults.Jitter
+	}
+	return schedule
 }
 
-func (s *CronService) addJob(name string, jobFunc func(ctx context.Context) error) error {
-	_, err := s.cron.AddFunc("0 0 0 * * *", func() { // Run every day at 00:00
-		log.Printf("Running %s job...", name)
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-		defer cancel()
-		err := jobFunc(ctx)
-		if err != nil {
-			log.Printf("Error running %s job: %v", name, err)
-		} else {
-			log.Printf("%s job completed at %s", name, time.Now().Format(time.DateTime))
+// AddProvider schedules the refresh of a single provider with the given
+// scraper. It fails if a job already exists for the provider.
+func (s *CronService) AddProvider(def *providers.Definition, scraper providers.Scraper) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[def.ID]; ok {
+		return fmt.Errorf("a job for %s already exists", def.ID)
+	}
+	return s.addJob(def, scraper)
+}
 
-		}
-	})
+// ReplaceProvider schedules the refresh of a provider, replacing its
+// previous job if any. The previous job is only removed once the new one is
+// scheduled, it keeps running when the new schedule is invalid.
+func (s *CronService) ReplaceProvider(def *providers.Definition, scraper providers.Scraper) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, replaced := s.jobs[def.ID]
+	if err := s.addJob(def, scraper); err != nil {
+		return err
+	}
+	if replaced {
+		s.cron.Remove(old.entryID)
+	}
+	return nil
+}
+
+func (s *CronService) addJob(def *providers.Definition, scraper providers.Scraper) error {
+	j := &job{
+		id:       def.ID,
+		name:     def.Title,
+		schedule: s.schedule(def.Schedule),
+		scraper:  scraper,
+	}
+	entryID, err := s.cron.AddJob(j.schedule.Cron, j)
 	if err != nil {
-		return fmt.Errorf("error adding %s job: %w", name, err)
+		return fmt.Errorf("error adding %s job: %w", j.name, err)
 	}
-	log.Printf("%s job added to cron.", name)
+	j.entryID = entryID
+	s.jobs[def.ID] = j
+	log.Printf("%s job added to cron with schedule %q.", j.name, j.schedule.Cron)
 	return nil
+}
 
+// AddProviders schedules the refresh of every provider of the registry.
+func (s *CronService) AddProviders(registry *providers.Registry) error {
+	for _, def := range registry.Definitions() {
+		scraper, ok := registry.Scraper(def.ID)
+		if !ok {
+			continue
+		}
+		if err := s.AddProvider(def, scraper); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (s *CronService) AddTheVergeJob() error {
-	return s.addJob("The Verge", func(ctx context.Context) error {
-		_, err := s.scraper.Scrape(ctx, "true")
-		return err
-	})
+// RemoveJob unschedules the job of a provider and reports whether it existed.
+func (s *CronService) RemoveJob(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return false
+	}
+	s.cron.Remove(j.entryID)
+	delete(s.jobs, id)
+	log.Printf("%s job removed from cron.", j.name)
+	return true
 }
 
-func (s *CronService) AddFreeCodeCampJob() error {
-	return s.addJob("FreeCodeCamp", func(ctx context.Context) error {
-		_, err := s.scraper.Scrape(ctx, "true")
-		return err
-	})
+// Run runs the job of a provider immediately, outside of its schedule.
+func (s *CronService) Run(id string) error {
+	s.mu.RLock()
+	j, ok := s.jobs[id]
+	s.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no job for %s", id)
+	}
+	return j.run()
 }
 
-func (s *CronService) AddAWSJob() error {
-	return s.addJob("AWS-Blog", func(ctx context.Context) error {
-		_, err := s.scraper.Scrape(ctx, "true")
-		return err
-	})
+// Job returns the status of the job of a provider.
+func (s *CronService) Job(id string) (JobStatus, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return JobStatus{}, false
+	}
+	return s.status(j), true
 }
 
-func (s *CronService) AddCSSTricksJob() error {
-	return s.addJob("CSS-Tricks", func(ctx context.Context) error {
-		_, err := s.scraper.Scrape(ctx, "true")
-		return err
-	})
+// Jobs returns the status of every job sorted by provider id.
+func (s *CronService) Jobs() []JobStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		statuses = append(statuses, s.status(j))
+	}
+	sort.Slice(statuses, func(i, k int) bool { return statuses[i].ID < statuses[k].ID })
+	return statuses
 }
 
-func (s *CronService) AddNodeWeeklyJob() error {
-	return s.addJob("NodeWeekly", func(ctx context.Context) error {
-		_, err := s.scraper.Scrape(ctx, "true")
-		return err
-	})
+func (s *CronService) status(j *job) JobStatus {
+	status := JobStatus{
+		ID:       j.id,
+		Name:     j.name,
+		Schedule: j.schedule,
+	}
+	entry := s.cron.Entry(j.entryID)
+	status.NextRun = entry.Next
+	if status.NextRun.IsZero() && entry.Schedule != nil {
+		// The cron has not been started yet
+		status.NextRun = entry.Schedule.Next(time.Now())
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	status.LastRun = j.lastRun
+	status.Running = j.running
+	if j.lastError != nil {
+		status.LastError = j.lastError.Error()
+	}
+	return status
 }
@@ -0,0 +1,131 @@
+package cronService
+
+import (
+	"context"
+	"errors"
+	"rss-generator/providers"
+	feedService "rss-generator/services/feed"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockScraper records how many times it was scraped.
+type mockScraper struct {
+	mu    sync.Mutex
+	calls []string
+	err   error
+}
+
+func (m *mockScraper) Scrape(ctx context.Context, isJob ...string) (*feedService.Feed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, isJob...)
+	return &feedService.Feed{}, m.err
+}
+
+func (m *mockScraper) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.calls)
+}
+
+func testDefinition(id string) *providers.Definition {
+	return &providers.Definition{ID: id, Title: id, Schedule: providers.Schedule{Cron: "@hourly"}}
+}
+
+func TestJobsScrapeTheirOwnProvider(t *testing.T) {
+	service := NewCronService(providers.Schedule{})
+	scrapers := map[string]*mockScraper{}
+	for _, id := range []string{"aws", "nodeweekly", "theverge"} {
+		scrapers[id] = &mockScraper{}
+		assert.NoError(t, service.AddProvider(testDefinition(id), scrapers[id]))
+	}
+
+	// Run the jobs the way the cron does
+	for _, id := range []string{"aws", "nodeweekly", "theverge"} {
+		service.mu.RLock()
+		entry := service.cron.Entry(service.jobs[id].entryID)
+		service.mu.RUnlock()
+		entry.Job.Run()
+
+		assert.Equal(t, []string{"true"}, scrapers[id].calls, id)
+	}
+	assert.Equal(t, 1, scrapers["aws"].count())
+	assert.Equal(t, 1, scrapers["nodeweekly"].count())
+	assert.Equal(t, 1, scrapers["theverge"].count())
+}
+
+func TestAddProviderDefaults(t *testing.T) {
+	service := NewCronService(providers.Schedule{Jitter: providers.Duration(time.Minute)})
+	def := testDefinition("aws")
+	def.Schedule = providers.Schedule{}
+	assert.NoError(t, service.AddProvider(def, &mockScraper{}))
+	assert.Error(t, service.AddProvider(def, &mockScraper{}))
+
+	status, ok := service.Job("aws")
+	assert.True(t, ok)
+	assert.Equal(t, DefaultSchedule.Cron, status.Schedule.Cron)
+	assert.Equal(t, DefaultSchedule.Timeout, status.Schedule.Timeout)
+	assert.Equal(t, providers.Duration(time.Minute), status.Schedule.Jitter)
+	assert.False(t, status.NextRun.IsZero())
+	assert.True(t, status.LastRun.IsZero())
+
+	def.Schedule.Cron = "not a cron"
+	assert.Error(t, service.ReplaceProvider(def, &mockScraper{}))
+	// The previous job survives an invalid schedule
+	status, ok = service.Job("aws")
+	assert.True(t, ok)
+	assert.Equal(t, DefaultSchedule.Cron, status.Schedule.Cron)
+	assert.Len(t, service.cron.Entries(), 1)
+
+	def.Schedule.Cron = "@every 1h"
+	assert.NoError(t, service.ReplaceProvider(def, &mockScraper{}))
+	status, _ = service.Job("aws")
+	assert.Equal(t, "@every 1h", status.Schedule.Cron)
+	assert.Len(t, service.cron.Entries(), 1)
+}
+
+func TestJobsStatus(t *testing.T) {
+	service := NewCronService(providers.Schedule{})
+	failing := &mockScraper{err: errors.New("selector not found")}
+	assert.NoError(t, service.AddProvider(testDefinition("theverge"), &mockScraper{}))
+	assert.NoError(t, service.AddProvider(testDefinition("aws"), failing))
+
+	assert.NoError(t, service.Run("theverge"))
+	assert.Error(t, service.Run("aws"))
+	assert.Error(t, service.Run("unknown"))
+
+	jobs := service.Jobs()
+	assert.Len(t, jobs, 2)
+	assert.Equal(t, "aws", jobs[0].ID)
+	assert.Equal(t, "selector not found", jobs[0].LastError)
+	assert.False(t, jobs[0].LastRun.IsZero())
+	assert.Equal(t, "theverge", jobs[1].ID)
+	assert.Empty(t, jobs[1].LastError)
+	assert.Equal(t, "@hourly", jobs[1].Schedule.Cron)
+}
+
+func TestRemoveAndReplaceJob(t *testing.T) {
+	service := NewCronService(providers.Schedule{})
+	old, replacement := &mockScraper{}, &mockScraper{}
+	assert.NoError(t, service.AddProvider(testDefinition("theverge"), old))
+
+	def := testDefinition("theverge")
+	def.Schedule.Cron = "0 */5 * * * *"
+	assert.NoError(t, service.ReplaceProvider(def, replacement))
+	assert.Len(t, service.cron.Entries(), 1)
+
+	assert.NoError(t, service.Run("theverge"))
+	assert.Equal(t, 0, old.count())
+	assert.Equal(t, 1, replacement.count())
+	status, _ := service.Job("theverge")
+	assert.Equal(t, "0 */5 * * * *", status.Schedule.Cron)
+
+	assert.True(t, service.RemoveJob("theverge"))
+	assert.False(t, service.RemoveJob("theverge"))
+	assert.Empty(t, service.Jobs())
+	assert.Empty(t, service.cron.Entries())
+}
@@ -0,0 +1,63 @@
+package feedService
+
+import "time"
+
+// Article is the canonical item returned by every provider.
+type Article struct {
+	Title      string      `json:"title"`
+	Link       string      `json:"link"`
+	Summary    string      `json:"summary,omitempty"`
+	Content    string      `json:"content,omitempty"`
+	Authors    []string    `json:"authors,omitempty"`
+	Categories []string    `json:"categories,omitempty"`
+	Published  time.Time   `json:"published,omitempty"`
+	Updated    time.Time   `json:"updated,omitempty"`
+	Image      string      `json:"image,omitempty"`
+	Enclosures []Enclosure `json:"enclosures,omitempty"`
+	GUID       string      `json:"guid"`
+	Source     *Source     `json:"source,omitempty"` // set on the items of merged feeds
+}
+
+// Source is the provider an item of a merged feed comes from.
+type Source struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Link    string `json:"link"`
+	FeedURL string `json:"feedUrl,omitempty"`
+}
+
+// Enclosure is a media object attached to an article.
+type Enclosure struct {
+	URL    string `json:"url"`
+	Type   string `json:"type,omitempty"`
+	Length int64  `json:"length,omitempty"`
+}
+
+// Feed is the structured result of a scrape, rendered by Build.
+type Feed struct {
+	Title       string    `json:"title"`
+	Link        string    `json:"link"`
+	Description string    `json:"description"`
+	Updated     time.Time `json:"updated"`
+	Changed     time.Time `json:"changed,omitempty"` // date of the last scrape that changed the items
+	Items       []Article `json:"items"`
+}
+
+// LastModified returns the later of the date the items last changed and
+// the date of the newest item, or the date of the scrape when both are
+// unknown. It is never after the scrape. Item dates alone miss the items
+// dated by day or found late, which would leave it unchanged.
+func (f *Feed) LastModified() time.Time {
+	newest := f.Changed
+	for _, item := range f.Items {
+		for _, date := range []time.Time{item.Published, item.Updated} {
+			if date.After(newest) {
+				newest = date
+			}
+		}
+	}
+	if newest.IsZero() || (!f.Updated.IsZero() && newest.After(f.Updated)) {
+		return f.Updated
+	}
+	return newest
+}
@@ -0,0 +1,156 @@
+package feedService
+
+import (
+	"crypto/sha1"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const atomNS = "http://www.w3.org/2005/Atom"
+
+type Atom struct {
+	XMLName xml.Name    `xml:"feed"`
+	NS      string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  *AtomPerson `xml:"author,omitempty"`
+	Links   []AtomLink  `xml:"link"`
+	Entries []AtomEntry `xml:"entry"`
+}
+
+type AtomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Authors    []AtomPerson   `xml:"author"`
+	Links      []AtomLink     `xml:"link"`
+	Summary    *AtomText      `xml:"summary,omitempty"`
+	Content    *AtomText      `xml:"content,omitempty"`
+	Categories []AtomCategory `xml:"category"`
+	Source     *AtomSource    `xml:"source,omitempty"`
+}
+
+// AtomSource is the feed an entry of a merged feed comes from.
+type AtomSource struct {
+	ID    string     `xml:"id"`
+	Title string     `xml:"title"`
+	Links []AtomLink `xml:"link"`
+}
+
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
+
+type AtomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+}
+
+type AtomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type AtomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// atomID returns guid when it is already an absolute URI and otherwise a
+// stable urn:uuid derived from it, as Atom ids must be IRIs.
+func atomID(guid string) string {
+	if u, err := url.Parse(guid); err == nil && u.IsAbs() {
+		return guid
+	}
+	sum := sha1.Sum([]byte(guid))
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+func buildAtom(feed *Feed, opts Options) (string, error) {
+	// The date of the last change rather than of the scrape, so the feed
+	// stays byte for byte the same while its items do not change
+	updated := feed.LastModified()
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	atom := Atom{
+		NS:      atomNS,
+		ID:      atomID(feed.Link),
+		Title:   feed.Title,
+		Updated: updated.Format(time.RFC3339),
+		// Atom requires an author for every entry, entries without one
+		// inherit the feed author.
+		Author:  &AtomPerson{Name: feed.Title},
+		Links:   []AtomLink{{Href: feed.Link, Rel: "alternate", Type: "text/html"}},
+		Entries: []AtomEntry{},
+	}
+	if opts.SelfURL != "" {
+		atom.ID = opts.SelfURL
+		atom.Links = append(atom.Links, AtomLink{Href: opts.SelfURL, Rel: "self", Type: "application/atom+xml"})
+	}
+
+	for _, article := range feed.Items {
+		entryUpdated := article.Updated
+		if entryUpdated.IsZero() {
+			entryUpdated = article.Published
+		}
+		if entryUpdated.IsZero() {
+			entryUpdated = updated
+		}
+		guid := article.GUID
+		if guid == "" {
+			guid = article.Link
+		}
+
+		entry := AtomEntry{
+			ID:      atomID(guid),
+			Title:   article.Title,
+			Updated: entryUpdated.Format(time.RFC3339),
+			Links:   []AtomLink{{Href: article.Link, Rel: "alternate", Type: "text/html"}},
+		}
+		if !article.Published.IsZero() {
+			entry.Published = article.Published.Format(time.RFC3339)
+		}
+		for _, author := range article.Authors {
+			entry.Authors = append(entry.Authors, AtomPerson{Name: author})
+		}
+		if article.Summary != "" {
+			entry.Summary = &AtomText{Type: "text", Body: article.Summary}
+		}
+		if article.Content != "" {
+			entry.Content = &AtomText{Type: "html", Body: article.Content}
+		}
+		for _, category := range article.Categories {
+			entry.Categories = append(entry.Categories, AtomCategory{Term: category})
+		}
+		for _, enclosure := range article.Enclosures {
+			entry.Links = append(entry.Links, AtomLink{Href: enclosure.URL, Rel: "enclosure", Type: enclosure.Type, Length: enclosure.Length})
+		}
+		if source := article.Source; source != nil {
+			entry.Source = &AtomSource{
+				ID:    atomID(source.Link),
+				Title: source.Title,
+				Links: []AtomLink{{Href: source.Link, Rel: "alternate", Type: "text/html"}},
+			}
+			if source.FeedURL != "" {
+				entry.Source.ID = source.FeedURL
+				entry.Source.Links = append(entry.Source.Links, AtomLink{Href: source.FeedURL, Rel: "self", Type: "application/atom+xml"})
+			}
+		}
+		atom.Entries = append(atom.Entries, entry)
+	}
+
+	output, err := xml.MarshalIndent(atom, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return xml.Header + string(output), nil
+}
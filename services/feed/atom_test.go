@@ -0,0 +1,51 @@
+package feedService
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildAtom(t *testing.T) {
+	feed := testFeed()
+	feed.Items[1].GUID = "article-2"
+	xmlStr, err := Build(FormatAtom, feed, Options{SelfURL: "http://localhost:8080/feed/test/atom.xml"})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(xmlStr, xml.Header))
+	assert.Contains(t, xmlStr, `<feed xmlns="http://www.w3.org/2005/Atom">`)
+
+	var atom Atom
+	assert.NoError(t, xml.Unmarshal([]byte(xmlStr), &atom))
+	assert.Equal(t, "http://localhost:8080/feed/test/atom.xml", atom.ID)
+	assert.Equal(t, "2023-10-28T12:00:00Z", atom.Updated)
+	assert.Equal(t, "Test Feed", atom.Author.Name)
+	assert.Contains(t, atom.Links, AtomLink{Href: "https://www.example.com/", Rel: "alternate", Type: "text/html"})
+	assert.Contains(t, atom.Links, AtomLink{Href: "http://localhost:8080/feed/test/atom.xml", Rel: "self", Type: "application/atom+xml"})
+
+	assert.Len(t, atom.Entries, 2)
+	first := atom.Entries[0]
+	assert.Equal(t, "https://www.example.com/article1", first.ID)
+	assert.Equal(t, "2023-10-27T10:00:00Z", first.Updated)
+	assert.Equal(t, "2023-10-27T10:00:00Z", first.Published)
+	assert.Equal(t, []AtomPerson{{Name: "Jane Doe"}}, first.Authors)
+	assert.Equal(t, "Summary 1", first.Summary.Body)
+	assert.Equal(t, []AtomCategory{{Term: "go"}, {Term: "rss"}}, first.Categories)
+	assert.Contains(t, first.Links, AtomLink{Href: "https://www.example.com/a.mp3", Rel: "enclosure", Type: "audio/mpeg", Length: 42})
+
+	second := atom.Entries[1]
+	assert.True(t, strings.HasPrefix(second.ID, "urn:uuid:"))
+	assert.Equal(t, atomID("article-2"), second.ID)
+	assert.Equal(t, "2023-10-28T12:00:00Z", second.Updated)
+	assert.Empty(t, second.Published)
+}
+
+func TestFormatFromFileName(t *testing.T) {
+	format, ok := FormatFromFileName("atom.xml")
+	assert.True(t, ok)
+	assert.Equal(t, FormatAtom, format)
+	assert.Equal(t, "rss.xml", FileName(FormatRSS))
+	_, ok = FormatFromFileName("feed.txt")
+	assert.False(t, ok)
+}
@@ -0,0 +1,116 @@
+package feedService
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// commonLayouts are tried after the layouts of a DateParser.
+var commonLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	time.DateTime,
+	time.DateOnly,
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC822Z,
+	time.RFC822,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"Jan. 2, 2006",
+	"Monday, January 2, 2006",
+	"January 2 2006",
+	"Jan 2 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+	"02 Jan 2006",
+	"01/02/2006",
+}
+
+var (
+	relativeDate = regexp.MustCompile(`^(a|an|one|\d+)\s*(seconds?|secs?|s|minutes?|mins?|m|hours?|hrs?|h|days?|d|weeks?|w|months?|mo|years?|y)\s+ago$`)
+	spaces       = regexp.MustCompile(`\s+`)
+)
+
+// DateParser turns the raw dates found on web pages into time.Time.
+type DateParser struct {
+	Layouts  []string         // tried before the common layouts
+	Location *time.Location   // used for dates without a zone, defaults to UTC
+	Now      func() time.Time // reference for relative dates, defaults to time.Now
+}
+
+// ParseDate parses raw with the default DateParser.
+func ParseDate(raw string) (time.Time, error) {
+	return DateParser{}.Parse(raw)
+}
+
+// Parse understands ISO 8601, RFC 822/1123, "Month D, YYYY" and relative
+// dates such as "2 hours ago" or "yesterday".
+func (p DateParser) Parse(raw string) (time.Time, error) {
+	value := strings.TrimSpace(spaces.ReplaceAllString(raw, " "))
+	if value == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+	loc := p.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	for _, layouts := range [][]string{p.Layouts, commonLayouts} {
+		for _, layout := range layouts {
+			if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+				return t, nil
+			}
+		}
+	}
+	if t, ok := p.parseRelative(strings.ToLower(value)); ok {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", raw)
+}
+
+func (p DateParser) parseRelative(value string) (time.Time, bool) {
+	now := time.Now()
+	if p.Now != nil {
+		now = p.Now()
+	}
+	switch value {
+	case "now", "just now", "today":
+		return now, true
+	case "yesterday":
+		return now.AddDate(0, 0, -1), true
+	}
+
+	match := relativeDate.FindStringSubmatch(value)
+	if match == nil {
+		return time.Time{}, false
+	}
+	n := 1
+	if number, err := strconv.Atoi(match[1]); err == nil {
+		n = number
+	}
+	unit := match[2]
+	switch {
+	case strings.HasPrefix(unit, "mo"):
+		return now.AddDate(0, -n, 0), true
+	case strings.HasPrefix(unit, "s"):
+		return now.Add(-time.Duration(n) * time.Second), true
+	case strings.HasPrefix(unit, "m"):
+		return now.Add(-time.Duration(n) * time.Minute), true
+	case strings.HasPrefix(unit, "h"):
+		return now.Add(-time.Duration(n) * time.Hour), true
+	case strings.HasPrefix(unit, "d"):
+		return now.AddDate(0, 0, -n), true
+	case strings.HasPrefix(unit, "w"):
+		return now.AddDate(0, 0, -7*n), true
+	case strings.HasPrefix(unit, "y"):
+		return now.AddDate(-n, 0, 0), true
+	}
+	return time.Time{}, false
+}
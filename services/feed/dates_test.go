@@ -0,0 +1,47 @@
+package feedService
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDateParser(t *testing.T) {
+	now := time.Date(2025, 4, 2, 13, 0, 0, 0, time.UTC)
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	testCases := []struct {
+		name     string
+		parser   DateParser
+		raw      string
+		expected time.Time
+	}{
+		{name: "RFC 3339", raw: "2025-04-02T13:05:50+02:00", expected: time.Date(2025, 4, 2, 11, 5, 50, 0, time.UTC)},
+		{name: "ISO 8601 without zone", raw: "2023-10-28T10:00:00", expected: time.Date(2023, 10, 28, 10, 0, 0, 0, time.UTC)},
+		{name: "RFC 1123Z", raw: "Fri, 27 Oct 2023 10:00:00 +0000", expected: time.Date(2023, 10, 27, 10, 0, 0, 0, time.UTC)},
+		{name: "Month D, YYYY", raw: "October 27, 2023", expected: time.Date(2023, 10, 27, 0, 0, 0, 0, time.UTC)},
+		{name: "Short month", raw: "  Jan 2,\n 2023 ", expected: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{name: "Upper case month", raw: "27 OCT 2023", expected: time.Date(2023, 10, 27, 0, 0, 0, 0, time.UTC)},
+		{name: "Custom layout", parser: DateParser{Layouts: []string{"02.01.2006"}}, raw: "27.10.2023", expected: time.Date(2023, 10, 27, 0, 0, 0, 0, time.UTC)},
+		{name: "Location", parser: DateParser{Location: tokyo}, raw: "2023-10-28 09:00:00", expected: time.Date(2023, 10, 28, 0, 0, 0, 0, time.UTC)},
+		{name: "Hours ago", raw: "2 hours ago", expected: now.Add(-2 * time.Hour)},
+		{name: "An hour ago", raw: "An hour ago", expected: now.Add(-time.Hour)},
+		{name: "Short unit", raw: "5m ago", expected: now.Add(-5 * time.Minute)},
+		{name: "Months ago", raw: "3 months ago", expected: now.AddDate(0, -3, 0)},
+		{name: "Yesterday", raw: "Yesterday", expected: now.AddDate(0, 0, -1)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.parser.Now = func() time.Time { return now }
+			result, err := tc.parser.Parse(tc.raw)
+			assert.NoError(t, err)
+			assert.True(t, tc.expected.Equal(result), "expected %s, got %s", tc.expected, result)
+		})
+	}
+
+	for _, raw := range []string{"", "invalid-date", "2 fortnights ago"} {
+		_, err := ParseDate(raw)
+		assert.Error(t, err, raw)
+	}
+}
@@ -0,0 +1,67 @@
+package feedService
+
+import "fmt"
+
+// Format is an output format a Feed can be rendered to.
+type Format string
+
+const (
+	FormatRSS  Format = "rss"
+	FormatAtom Format = "atom"
+	FormatJSON Format = "json"
+)
+
+// Options carries the request specific details of a rendered feed.
+type Options struct {
+	SelfURL string // public URL the feed is served from
+}
+
+type formatInfo struct {
+	fileName    string // last segment of the feed URL
+	contentType string
+	build       func(feed *Feed, opts Options) (string, error)
+}
+
+// formats lists the supported formats in the order they are advertised.
+var formats = []Format{FormatRSS, FormatAtom, FormatJSON}
+
+var formatInfos = map[Format]formatInfo{
+	FormatRSS:  {fileName: "rss.xml", contentType: "application/rss+xml; charset=utf-8", build: buildRSS},
+	FormatAtom: {fileName: "atom.xml", contentType: "application/atom+xml; charset=utf-8", build: buildAtom},
+	FormatJSON: {fileName: "feed.json", contentType: "application/feed+json; charset=utf-8", build: buildJSONFeed},
+}
+
+// Build renders the feed in the given format.
+func Build(format Format, feed *Feed, opts Options) (string, error) {
+	info, ok := formatInfos[format]
+	if !ok {
+		return "", fmt.Errorf("unsupported feed format %q", format)
+	}
+	return info.build(feed, opts)
+}
+
+// Formats returns every supported format.
+func Formats() []Format {
+	return append([]Format(nil), formats...)
+}
+
+// FormatFromFileName returns the format served under a file name such as
+// rss.xml.
+func FormatFromFileName(name string) (Format, bool) {
+	for format, info := range formatInfos {
+		if info.fileName == name {
+			return format, true
+		}
+	}
+	return "", false
+}
+
+// FileName returns the file name the format is served under.
+func FileName(format Format) string {
+	return formatInfos[format].fileName
+}
+
+// ContentType returns the HTTP content type of the format.
+func ContentType(format Format) string {
+	return formatInfos[format].contentType
+}
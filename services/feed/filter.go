@@ -0,0 +1,159 @@
+package feedService
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filter selects the items of a feed, see ParseFilter for its query
+// parameters. The zero value keeps every item.
+type Filter struct {
+	Limit      int       // maximum number of items, 0 means no limit
+	Include    []string  // keywords of which the title or description must contain one
+	Exclude    []string  // keywords the title and description must not contain
+	Categories []string  // categories of which the item must have one
+	Authors    []string  // authors of which the item must have one
+	Since      time.Time // oldest publication date, undated items are dropped
+}
+
+// filterParams are the query parameters read by ParseFilter.
+var filterParams = []string{"limit", "include", "exclude", "category", "author", "since"}
+
+// ParseFilter reads a filter from the query of a feed URL:
+//
+//	limit=10                  at most 10 items
+//	include=go,rust           title or description containing go or rust
+//	exclude=sponsored         title and description without sponsored
+//	category=Compute,Storage  in one of the categories
+//	author=Jane Doe           by one of the authors
+//	since=2024-01-31          published since a date, a RFC 3339 time or a duration such as 48h
+//
+// Values are comma separated and parameters may be repeated. Keywords,
+// categories and authors are matched case-insensitively.
+func ParseFilter(query url.Values) (Filter, error) {
+	var filter Filter
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			return filter, fmt.Errorf("invalid limit %q", raw)
+		}
+		filter.Limit = limit
+	}
+	filter.Include = listParam(query, "include")
+	filter.Exclude = listParam(query, "exclude")
+	filter.Categories = listParam(query, "category")
+	filter.Authors = listParam(query, "author")
+	if raw := query.Get("since"); raw != "" {
+		since, err := parseSince(raw, time.Now())
+		if err != nil {
+			return filter, err
+		}
+		filter.Since = since
+	}
+	return filter, nil
+}
+
+// listParam returns the lowercased values of a repeated, comma separated
+// parameter.
+func listParam(query url.Values, name string) []string {
+	var values []string
+	for _, raw := range query[name] {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func parseSince(raw string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(raw); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid since %q, expected a date, a RFC 3339 time or a duration", raw)
+}
+
+// FilterQuery returns the filter parameters of a query in a canonical form,
+// empty when the query has none.
+func FilterQuery(query url.Values) string {
+	canonical := url.Values{}
+	for _, name := range filterParams {
+		if values, ok := query[name]; ok {
+			canonical[name] = values
+		}
+	}
+	return canonical.Encode()
+}
+
+// IsZero reports whether the filter keeps every item.
+func (f Filter) IsZero() bool {
+	return f.Limit == 0 && len(f.Include) == 0 && len(f.Exclude) == 0 &&
+		len(f.Categories) == 0 && len(f.Authors) == 0 && f.Since.IsZero()
+}
+
+// Apply returns a copy of the feed with the items matching the filter.
+func (f Filter) Apply(feed *Feed) *Feed {
+	filtered := *feed
+	filtered.Items = make([]Article, 0, len(feed.Items))
+	for _, item := range feed.Items {
+		if f.Limit > 0 && len(filtered.Items) >= f.Limit {
+			break
+		}
+		if f.Match(item) {
+			filtered.Items = append(filtered.Items, item)
+		}
+	}
+	return &filtered
+}
+
+// Match reports whether an item passes the filter, ignoring the limit.
+func (f Filter) Match(item Article) bool {
+	description := item.Summary
+	if description == "" {
+		description = item.Content
+	}
+	text := strings.ToLower(item.Title + "\n" + description)
+	if len(f.Include) > 0 && !slices.ContainsFunc(f.Include, func(k string) bool { return strings.Contains(text, k) }) {
+		return false
+	}
+	if slices.ContainsFunc(f.Exclude, func(k string) bool { return strings.Contains(text, k) }) {
+		return false
+	}
+	if len(f.Categories) > 0 && !containsFold(item.Categories, f.Categories) {
+		return false
+	}
+	if len(f.Authors) > 0 && !containsFold(item.Authors, f.Authors) {
+		return false
+	}
+	if !f.Since.IsZero() {
+		date := item.Published
+		if date.IsZero() {
+			date = item.Updated
+		}
+		if date.IsZero() || date.Before(f.Since) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFold reports whether one of values is in wanted, which is
+// lowercased.
+func containsFold(values, wanted []string) bool {
+	for _, value := range values {
+		if slices.Contains(wanted, strings.ToLower(strings.TrimSpace(value))) {
+			return true
+		}
+	}
+	return false
+}
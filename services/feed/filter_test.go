@@ -0,0 +1,70 @@
+package feedService
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func filterFeed() *Feed {
+	return &Feed{
+		Title:   "Test Feed",
+		Updated: time.Date(2023, 10, 28, 12, 0, 0, 0, time.UTC),
+		Items: []Article{
+			{Title: "Go 1.22 released", Summary: "Range over integers", Authors: []string{"Jane Doe"}, Categories: []string{"Go"}, Published: time.Date(2023, 10, 27, 0, 0, 0, 0, time.UTC), GUID: "1"},
+			{Title: "Sponsored: a database", Summary: "Try it now", Authors: []string{"Ads"}, Categories: []string{"Databases"}, Published: time.Date(2023, 10, 26, 0, 0, 0, 0, time.UTC), GUID: "2"},
+			{Title: "Rust and Go", Content: "<p>Both are fine</p>", Authors: []string{"John Roe", "jane doe"}, Categories: []string{"Rust", "go"}, GUID: "3"},
+			{Title: "CSS grid", Summary: "Layouts", Authors: []string{"Chris"}, Categories: []string{"CSS"}, Published: time.Date(2023, 10, 20, 0, 0, 0, 0, time.UTC), GUID: "4"},
+		},
+	}
+}
+
+func filterGUIDs(t *testing.T, query string) []string {
+	values, err := url.ParseQuery(query)
+	assert.NoError(t, err)
+	filter, err := ParseFilter(values)
+	assert.NoError(t, err)
+	guids := []string{}
+	for _, item := range filter.Apply(filterFeed()).Items {
+		guids = append(guids, item.GUID)
+	}
+	return guids
+}
+
+func TestFilter_Apply(t *testing.T) {
+	assert.Equal(t, []string{"1", "2", "3", "4"}, filterGUIDs(t, ""))
+	assert.Equal(t, []string{"1", "2"}, filterGUIDs(t, "limit=2"))
+	assert.Equal(t, []string{"1", "3"}, filterGUIDs(t, "include=GO"))
+	assert.Equal(t, []string{"3", "4"}, filterGUIDs(t, "include=both,layouts"), "the description falls back to the content")
+	assert.Equal(t, []string{"1", "3", "4"}, filterGUIDs(t, "exclude=sponsored"))
+	assert.Equal(t, []string{"1", "3"}, filterGUIDs(t, "category=go"))
+	assert.Equal(t, []string{"1", "3", "4"}, filterGUIDs(t, "category=go&category=css"))
+	assert.Equal(t, []string{"1", "3"}, filterGUIDs(t, "author=Jane+Doe"))
+	assert.Equal(t, []string{"1", "2"}, filterGUIDs(t, "since=2023-10-26"), "undated items are dropped")
+	assert.Equal(t, []string{"1"}, filterGUIDs(t, "since=2023-10-26T12:00:00Z"))
+	assert.Equal(t, []string{"3"}, filterGUIDs(t, "include=go&author=john+roe&limit=5"))
+	assert.Equal(t, []string{"1"}, filterGUIDs(t, "include=go&limit=1"))
+}
+
+func TestParseFilter(t *testing.T) {
+	filter, err := ParseFilter(url.Values{"since": {"48h"}})
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(-48*time.Hour), filter.Since, time.Minute)
+	assert.False(t, filter.IsZero())
+
+	filter, err = ParseFilter(url.Values{"other": {"1"}})
+	assert.NoError(t, err)
+	assert.True(t, filter.IsZero())
+
+	for _, query := range []url.Values{{"limit": {"ten"}}, {"limit": {"-1"}}, {"since": {"yesterday"}}} {
+		_, err := ParseFilter(query)
+		assert.Error(t, err, query.Encode())
+	}
+}
+
+func TestFilterQuery(t *testing.T) {
+	assert.Equal(t, "", FilterQuery(url.Values{"utm_source": {"bot"}}))
+	assert.Equal(t, "category=go&limit=5", FilterQuery(url.Values{"limit": {"5"}, "category": {"go"}, "utm_source": {"bot"}}))
+}
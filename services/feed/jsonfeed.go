@@ -0,0 +1,111 @@
+package feedService
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const jsonFeedVersion = "https://jsonfeed.org/version/1.1"
+
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url,omitempty"`
+	FeedURL     string         `json:"feed_url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Authors     []JSONAuthor   `json:"authors,omitempty"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url,omitempty"`
+	Title         string           `json:"title,omitempty"`
+	ContentHTML   string           `json:"content_html,omitempty"`
+	ContentText   *string          `json:"content_text,omitempty"`
+	Summary       string           `json:"summary,omitempty"`
+	Image         string           `json:"image,omitempty"`
+	DatePublished string           `json:"date_published,omitempty"`
+	DateModified  string           `json:"date_modified,omitempty"`
+	Authors       []JSONAuthor     `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+	Attachments   []JSONAttachment `json:"attachments,omitempty"`
+	Source        *JSONSource      `json:"_source,omitempty"` // extension, set on the items of merged feeds
+}
+
+// JSONSource is the feed an item of a merged feed comes from.
+type JSONSource struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	HomePageURL string `json:"home_page_url,omitempty"`
+	FeedURL     string `json:"feed_url,omitempty"`
+}
+
+type JSONAuthor struct {
+	Name string `json:"name"`
+}
+
+type JSONAttachment struct {
+	URL         string `json:"url"`
+	MimeType    string `json:"mime_type"`
+	SizeInBytes int64  `json:"size_in_bytes,omitempty"`
+}
+
+func buildJSONFeed(feed *Feed, opts Options) (string, error) {
+	jsonFeed := JSONFeed{
+		Version:     jsonFeedVersion,
+		Title:       feed.Title,
+		HomePageURL: feed.Link,
+		FeedURL:     opts.SelfURL,
+		Description: feed.Description,
+		Authors:     []JSONAuthor{{Name: feed.Title}},
+		Items:       []JSONFeedItem{},
+	}
+
+	for _, article := range feed.Items {
+		id := article.GUID
+		if id == "" {
+			id = article.Link
+		}
+		item := JSONFeedItem{
+			ID:          id,
+			URL:         article.Link,
+			Title:       article.Title,
+			ContentHTML: article.Content,
+			Summary:     article.Summary,
+			Image:       article.Image,
+			Tags:        article.Categories,
+		}
+		// JSON Feed requires content_html or content_text on every item.
+		if item.ContentHTML == "" {
+			text := article.Summary
+			item.ContentText = &text
+		}
+		if !article.Published.IsZero() {
+			item.DatePublished = article.Published.Format(time.RFC3339)
+		}
+		if !article.Updated.IsZero() {
+			item.DateModified = article.Updated.Format(time.RFC3339)
+		}
+		for _, author := range article.Authors {
+			item.Authors = append(item.Authors, JSONAuthor{Name: author})
+		}
+		if source := article.Source; source != nil {
+			item.Source = &JSONSource{ID: source.ID, Title: source.Title, HomePageURL: source.Link, FeedURL: source.FeedURL}
+		}
+		for _, enclosure := range article.Enclosures {
+			mimeType := enclosure.Type
+			if mimeType == "" {
+				mimeType = "application/octet-stream"
+			}
+			item.Attachments = append(item.Attachments, JSONAttachment{URL: enclosure.URL, MimeType: mimeType, SizeInBytes: enclosure.Length})
+		}
+		jsonFeed.Items = append(jsonFeed.Items, item)
+	}
+
+	output, err := json.MarshalIndent(jsonFeed, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
@@ -0,0 +1,36 @@
+package feedService
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildJSONFeed(t *testing.T) {
+	feed := testFeed()
+	feed.Items[1].Content = "<p>Body 2</p>"
+	body, err := Build(FormatJSON, feed, Options{SelfURL: "http://localhost:8080/feed/test/feed.json"})
+	assert.NoError(t, err)
+
+	var jsonFeed JSONFeed
+	assert.NoError(t, json.Unmarshal([]byte(body), &jsonFeed))
+	assert.Equal(t, "https://jsonfeed.org/version/1.1", jsonFeed.Version)
+	assert.Equal(t, "Test Feed", jsonFeed.Title)
+	assert.Equal(t, "https://www.example.com/", jsonFeed.HomePageURL)
+	assert.Equal(t, "http://localhost:8080/feed/test/feed.json", jsonFeed.FeedURL)
+
+	assert.Len(t, jsonFeed.Items, 2)
+	first := jsonFeed.Items[0]
+	assert.Equal(t, "https://www.example.com/article1", first.ID)
+	assert.Equal(t, "Summary 1", *first.ContentText)
+	assert.Equal(t, "2023-10-27T10:00:00Z", first.DatePublished)
+	assert.Equal(t, []JSONAuthor{{Name: "Jane Doe"}}, first.Authors)
+	assert.Equal(t, []string{"go", "rss"}, first.Tags)
+	assert.Equal(t, []JSONAttachment{{URL: "https://www.example.com/a.mp3", MimeType: "audio/mpeg", SizeInBytes: 42}}, first.Attachments)
+
+	second := jsonFeed.Items[1]
+	assert.Equal(t, "<p>Body 2</p>", second.ContentHTML)
+	assert.Nil(t, second.ContentText)
+	assert.Empty(t, second.DatePublished)
+}
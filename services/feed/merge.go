@@ -0,0 +1,49 @@
+package feedService
+
+import (
+	"sort"
+	"time"
+)
+
+// Merge combines the items of several feeds into feed, newest first. Items
+// sharing a GUID or a link with a newer one are dropped. The Updated date of
+// feed is the newest of the merged feeds.
+func Merge(feed *Feed, feeds ...*Feed) *Feed {
+	merged := *feed
+	merged.Items = nil
+	for _, f := range feeds {
+		merged.Items = append(merged.Items, f.Items...)
+		if f.Updated.After(merged.Updated) {
+			merged.Updated = f.Updated
+		}
+		if f.Changed.After(merged.Changed) {
+			merged.Changed = f.Changed
+		}
+	}
+
+	// Undated items go last, in the order of their feed
+	sort.SliceStable(merged.Items, func(i, j int) bool {
+		return itemDate(merged.Items[i]).After(itemDate(merged.Items[j]))
+	})
+
+	seen := map[string]bool{}
+	items := make([]Article, 0, len(merged.Items))
+	for _, item := range merged.Items {
+		if (item.GUID != "" && seen["guid:"+item.GUID]) || (item.Link != "" && seen["link:"+item.Link]) {
+			continue
+		}
+		seen["guid:"+item.GUID] = item.GUID != ""
+		seen["link:"+item.Link] = item.Link != ""
+		items = append(items, item)
+	}
+	merged.Items = items
+	return &merged
+}
+
+// itemDate is the date an item is sorted by.
+func itemDate(item Article) time.Time {
+	if !item.Published.IsZero() {
+		return item.Published
+	}
+	return item.Updated
+}
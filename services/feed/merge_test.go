@@ -0,0 +1,57 @@
+package feedService
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2023, 10, d, 0, 0, 0, 0, time.UTC) }
+	a := &Feed{Updated: day(28), Items: []Article{
+		{Title: "a1", GUID: "a1", Link: "https://a.example.com/1", Published: day(27)},
+		{Title: "a2", GUID: "a2", Link: "https://a.example.com/2"},
+		{Title: "a3", GUID: "a3", Link: "https://a.example.com/3", Published: day(20)},
+	}}
+	b := &Feed{Updated: day(29), Items: []Article{
+		{Title: "b1", GUID: "b1", Link: "https://b.example.com/1", Updated: day(25)},
+		{Title: "b2", GUID: "a1", Link: "https://b.example.com/2", Published: day(26)},
+		{Title: "b3", GUID: "b3", Link: "https://a.example.com/3", Published: day(21)},
+	}}
+
+	merged := Merge(&Feed{Title: "Merged"}, a, b)
+	assert.Equal(t, "Merged", merged.Title)
+	assert.Equal(t, day(29), merged.Updated)
+	titles := []string{}
+	for _, item := range merged.Items {
+		titles = append(titles, item.Title)
+	}
+	// b2 shares the GUID of a1 and a3 the link of the newer b3
+	assert.Equal(t, []string{"a1", "b1", "b3", "a2"}, titles)
+}
+
+func TestBuild_Source(t *testing.T) {
+	feed := testFeed()
+	feed.Items[0].Source = &Source{
+		ID:      "aws",
+		Title:   "AWS Blog",
+		Link:    "https://aws.amazon.com/blogs/",
+		FeedURL: "https://rss.example.com/feed/aws/rss.xml",
+	}
+
+	rss, err := Build(FormatRSS, feed, Options{})
+	assert.NoError(t, err)
+	assert.Contains(t, rss, `<source url="https://rss.example.com/feed/aws/rss.xml">AWS Blog</source>`)
+
+	atom, err := Build(FormatAtom, feed, Options{})
+	assert.NoError(t, err)
+	assert.Contains(t, atom, "<source>")
+	assert.Contains(t, atom, "<id>https://rss.example.com/feed/aws/rss.xml</id>")
+	assert.Contains(t, atom, "<title>AWS Blog</title>")
+
+	json, err := Build(FormatJSON, feed, Options{})
+	assert.NoError(t, err)
+	assert.Contains(t, json, `"_source"`)
+	assert.Contains(t, json, `"home_page_url": "https://aws.amazon.com/blogs/"`)
+}
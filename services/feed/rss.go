@@ -0,0 +1,113 @@
+package feedService
+
+import (
+	"encoding/xml"
+	"strings"
+	"time"
+)
+
+// contentNS is the namespace of the content:encoded element.
+const contentNS = "http://purl.org/rss/1.0/modules/content/"
+
+type RSS struct {
+	XMLName   xml.Name `xml:"rss"`
+	Version   string   `xml:"version,attr"`
+	ContentNS string   `xml:"xmlns:content,attr,omitempty"`
+	Channel   Channel  `xml:"channel"`
+}
+
+type Channel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	PubDate     string    `xml:"pubDate"`
+	Items       []RSSItem `xml:"item"`
+}
+
+type RSSItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	Description string        `xml:"description"`
+	PubDate     string        `xml:"pubDate,omitempty"`
+	GUID        string        `xml:"guid"`
+	Author      string        `xml:"author,omitempty"`
+	Categories  []string      `xml:"category"`
+	Enclosure   *RSSEnclosure `xml:"enclosure,omitempty"`
+	Content     *RSSContent   `xml:"content:encoded,omitempty"`
+	Source      *RSSSource    `xml:"source,omitempty"`
+}
+
+// RSSSource is the feed an item of a merged feed comes from.
+type RSSSource struct {
+	URL   string `xml:"url,attr"`
+	Title string `xml:",chardata"`
+}
+
+// RSSContent is the full HTML of an item, written as CDATA.
+type RSSContent struct {
+	Body string `xml:",cdata"`
+}
+
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length int64  `xml:"length,attr"`
+}
+
+func buildRSS(feed *Feed, opts Options) (string, error) {
+	// The date of the last change rather than of the scrape, so the feed
+	// stays byte for byte the same while its items do not change
+	updated := feed.LastModified()
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	rss := RSS{
+		XMLName: xml.Name{Local: "rss"},
+		Version: "2.0",
+		Channel: Channel{
+			Title:       feed.Title,
+			Link:        feed.Link,
+			Description: feed.Description,
+			PubDate:     updated.Format(time.RFC1123Z),
+			Items:       []RSSItem{},
+		},
+	}
+
+	for _, article := range feed.Items {
+		description := article.Summary
+		if description == "" {
+			description = article.Content
+		}
+
+		rssItem := RSSItem{
+			Title:       article.Title,
+			Link:        article.Link,
+			Description: description,
+			GUID:        article.GUID,
+			Author:      strings.Join(article.Authors, ", "),
+			Categories:  article.Categories,
+		}
+		if !article.Published.IsZero() {
+			rssItem.PubDate = article.Published.Format(time.RFC1123Z)
+		}
+		if len(article.Enclosures) > 0 {
+			enclosure := article.Enclosures[0]
+			rssItem.Enclosure = &RSSEnclosure{URL: enclosure.URL, Type: enclosure.Type, Length: enclosure.Length}
+		}
+		if article.Source != nil {
+			rssItem.Source = &RSSSource{URL: article.Source.FeedURL, Title: article.Source.Title}
+		}
+		if article.Content != "" {
+			rss.ContentNS = contentNS
+			rssItem.Content = &RSSContent{Body: article.Content}
+		}
+		rss.Channel.Items = append(rss.Channel.Items, rssItem)
+	}
+
+	output, err := xml.MarshalIndent(rss, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return xml.Header + string(output), nil
+}
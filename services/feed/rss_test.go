@@ -0,0 +1,69 @@
+package feedService
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testFeed() *Feed {
+	return &Feed{
+		Title:       "Test Feed",
+		Link:        "https://www.example.com/",
+		Description: "Test Description",
+		Updated:     time.Date(2023, 10, 29, 12, 0, 0, 0, time.UTC),
+		Changed:     time.Date(2023, 10, 28, 12, 0, 0, 0, time.UTC),
+		Items: []Article{
+			{
+				Title:      "Test Article 1",
+				Link:       "https://www.example.com/article1",
+				Summary:    "Summary 1",
+				Authors:    []string{"Jane Doe"},
+				Categories: []string{"go", "rss"},
+				Published:  time.Date(2023, 10, 27, 10, 0, 0, 0, time.UTC),
+				GUID:       "https://www.example.com/article1",
+				Enclosures: []Enclosure{{URL: "https://www.example.com/a.mp3", Type: "audio/mpeg", Length: 42}},
+			},
+			{
+				Title: "Test Article 2",
+				Link:  "https://www.example.com/article2",
+				GUID:  "https://www.example.com/article2",
+			},
+		},
+	}
+}
+
+func TestBuildRSS(t *testing.T) {
+	xmlStr, err := Build(FormatRSS, testFeed(), Options{})
+	assert.NoError(t, err)
+	assert.Contains(t, xmlStr, `<rss version="2.0">`)
+	assert.Contains(t, xmlStr, "<title>Test Feed</title>")
+	assert.Contains(t, xmlStr, "<title>Test Article 1</title>")
+	assert.Contains(t, xmlStr, "<description>Summary 1</description>")
+	assert.Contains(t, xmlStr, "<author>Jane Doe</author>")
+	assert.Contains(t, xmlStr, "<category>go</category>")
+	assert.Contains(t, xmlStr, "<category>rss</category>")
+	assert.Contains(t, xmlStr, `<enclosure url="https://www.example.com/a.mp3" type="audio/mpeg" length="42"></enclosure>`)
+	assert.Contains(t, xmlStr, "<guid>https://www.example.com/article2</guid>")
+	assert.Contains(t, xmlStr, "<pubDate>Sat, 28 Oct 2023 12:00:00 +0000</pubDate>", "dated by the last change, not by the scrape")
+}
+
+func TestBuildRSS_Content(t *testing.T) {
+	feed := testFeed()
+	xmlStr, err := Build(FormatRSS, feed, Options{})
+	assert.NoError(t, err)
+	assert.NotContains(t, xmlStr, "xmlns:content")
+
+	feed.Items[0].Content = "<p>Full article</p>"
+	xmlStr, err = Build(FormatRSS, feed, Options{})
+	assert.NoError(t, err)
+	assert.Contains(t, xmlStr, `<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">`)
+	assert.Contains(t, xmlStr, "<content:encoded><![CDATA[<p>Full article</p>]]></content:encoded>")
+	assert.Contains(t, xmlStr, "<description>Summary 1</description>")
+}
+
+func TestBuildUnsupportedFormat(t *testing.T) {
+	_, err := Build(Format("txt"), testFeed(), Options{})
+	assert.Error(t, err)
+}
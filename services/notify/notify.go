@@ -0,0 +1,104 @@
+package notifyService
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Kinds of Alert.
+const (
+	AlertFailing   = "failing"   // the scrapes of a provider started to fail
+	AlertRecovered = "recovered" // a provider scraped successfully again
+)
+
+// Alert reports a change in the health of a provider.
+type Alert struct {
+	Kind     string    `json:"kind"` // AlertFailing or AlertRecovered
+	Provider string    `json:"provider"`
+	Title    string    `json:"title"`
+	URL      string    `json:"url"`             // page scraped
+	Error    string    `json:"error,omitempty"` // why the scrape failed
+	Time     time.Time `json:"time"`
+}
+
+// String returns a one line description of the alert.
+func (a Alert) String() string {
+	if a.Kind == AlertRecovered {
+		return fmt.Sprintf("%s (%s) recovered", a.Title, a.Provider)
+	}
+	return fmt.Sprintf("%s (%s) is failing: %s", a.Title, a.Provider, a.Error)
+}
+
+// Notifier delivers alerts.
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// NotifierFunc adapts a function to the Notifier interface.
+type NotifierFunc func(ctx context.Context, alert Alert) error
+
+func (f NotifierFunc) Notify(ctx context.Context, alert Alert) error {
+	return f(ctx, alert)
+}
+
+// LogNotifier writes the alerts to the log.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, alert Alert) error {
+	log.Printf("Alert: %s", alert)
+	return nil
+}
+
+// WebhookNotifier posts the alerts as JSON to a URL. The text field makes
+// the payload readable by Slack and Mattermost incoming webhooks.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client // http.DefaultClient when nil
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(struct {
+		Alert
+		Text string `json:"text"`
+	}{alert, alert.String()})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// Multi delivers the alerts to every notifier.
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, alert Alert) error {
+	var errs []error
+	for _, notifier := range m {
+		if err := notifier.Notify(ctx, alert); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,55 @@
+package notifyService
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	alert := Alert{
+		Kind:     AlertFailing,
+		Provider: "example",
+		Title:    "Example",
+		Error:    "2 items, expected at least 5",
+		Time:     time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC),
+	}
+	notifier := &WebhookNotifier{URL: server.URL}
+	assert.NoError(t, notifier.Notify(context.Background(), alert))
+	assert.Equal(t, "failing", received["kind"])
+	assert.Equal(t, "example", received["provider"])
+	assert.Equal(t, "Example (example) is failing: 2 items, expected at least 5", received["text"])
+
+	failing := &WebhookNotifier{URL: server.URL + "/missing"}
+	server.Config.Handler = http.NotFoundHandler()
+	assert.EqualError(t, failing.Notify(context.Background(), alert), "webhook answered 404 Not Found")
+}
+
+func TestMulti_Notify(t *testing.T) {
+	var calls int
+	ok := NotifierFunc(func(ctx context.Context, alert Alert) error {
+		calls++
+		return nil
+	})
+	broken := NotifierFunc(func(ctx context.Context, alert Alert) error {
+		return errors.New("unreachable")
+	})
+
+	err := Multi{broken, ok, LogNotifier{}}.Notify(context.Background(), Alert{Kind: AlertRecovered})
+	assert.EqualError(t, err, "unreachable")
+	assert.Equal(t, 1, calls, "a failing notifier does not stop the others")
+}
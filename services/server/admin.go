@@ -0,0 +1,69 @@
+package serverService
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"rss-generator/providers"
+	"strings"
+)
+
+// RefreshResponse is the result of POST /admin/providers/{id}/refresh.
+type RefreshResponse struct {
+	ID string `json:"id"`
+	providers.RefreshResult
+}
+
+// admin guards an admin handler with the bearer token of the server. The
+// admin endpoints are disabled when no token is set.
+func (s *Server) admin(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.AdminToken == "" {
+			http.Error(w, "admin endpoints are disabled, set ADMIN_TOKEN to enable them", http.StatusForbidden)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.AdminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// handleRefresh serves POST /admin/providers/{id}/refresh, scraping the
+// provider right away like its cron job does. A failed scrape is answered
+// with 502 and the run, so its error and duration are reported too.
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	scraper, ok := s.registry.Scraper(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	refresher, ok := scraper.(providers.Refresher)
+	if !ok {
+		http.Error(w, "provider "+id+" cannot be refreshed", http.StatusNotImplemented)
+		return
+	}
+
+	log.Printf("Refreshing %s on demand...", id)
+	result, err := refresher.Refresh(r.Context())
+	status := http.StatusOK
+	switch {
+	case errors.Is(err, providers.ErrScrapeRunning):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil && result.Outcome == "":
+		// The request was canceled before the end of the scrape
+		log.Printf("Error refreshing %s: %v", id, err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	case err != nil:
+		log.Printf("Error refreshing %s: %v", id, err)
+		status = http.StatusBadGateway
+	}
+	writeJSONStatus(w, status, RefreshResponse{ID: id, RefreshResult: result})
+}
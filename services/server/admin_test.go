@@ -0,0 +1,75 @@
+package serverService
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"rss-generator/providers"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func post(s *Server, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_Refresh(t *testing.T) {
+	s := testServer(t)
+
+	assert.Equal(t, http.StatusForbidden, post(s, "/admin/providers/example/refresh", "").Code, "disabled without a token")
+
+	s.AdminToken = "secret"
+	rec := post(s, "/admin/providers/example/refresh", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, `Bearer realm="admin"`, rec.Header().Get("WWW-Authenticate"))
+	assert.Equal(t, http.StatusUnauthorized, post(s, "/admin/providers/example/refresh", "wrong").Code)
+	assert.Equal(t, http.StatusNotFound, post(s, "/admin/providers/unknown/refresh", "secret").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, get(s, "/admin/providers/example/refresh", map[string]string{"Authorization": "Bearer secret"}).Code)
+
+	rec = post(s, "/admin/providers/example/refresh", "secret")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var response RefreshResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "example", response.ID)
+	assert.Equal(t, 2, response.Items)
+	assert.Equal(t, []string{"https://www.example.com/first", "https://www.example.com/second"}, response.Added)
+	assert.Empty(t, response.Removed)
+
+	// Nothing changed upstream
+	rec = post(s, "/admin/providers/example/refresh", "secret")
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Empty(t, response.Added)
+	assert.Empty(t, response.Removed)
+}
+
+func TestServer_Refresh_Failed(t *testing.T) {
+	s := testServer(t)
+	s.AdminToken = "secret"
+	registerUpstream(t, s.registry, "broken", "not a feed")
+
+	rec := post(s, "/admin/providers/broken/refresh", "secret")
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+	var response RefreshResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "broken", response.ID)
+	assert.Equal(t, providers.RunFailed, response.Outcome)
+	assert.NotEmpty(t, response.Error)
+	assert.Positive(t, response.Duration)
+	assert.Zero(t, response.Items)
+
+	rec = post(s, "/admin/providers/example/refresh", "secret")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	response = RefreshResponse{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, providers.RunSucceeded, response.Outcome)
+	assert.Empty(t, response.Error)
+	assert.Equal(t, 2, response.Found)
+}
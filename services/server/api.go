@@ -0,0 +1,132 @@
+package serverService
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"rss-generator/providers"
+	feedService "rss-generator/services/feed"
+	"time"
+)
+
+// ProviderInfo describes a provider in the JSON API.
+type ProviderInfo struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Link        string             `json:"link"`
+	URL         string             `json:"url"` // page or feed scraped
+	Schedule    providers.Schedule `json:"schedule"`
+	NextRun     time.Time          `json:"nextRun"`
+	LastSuccess time.Time          `json:"lastSuccess"`
+	LastError   string             `json:"lastError,omitempty"`
+	LastErrorAt time.Time          `json:"lastErrorAt"`
+	Failing     bool               `json:"failing"` // the last scrape failed, the last good feed is served
+	Items       int                `json:"items"`
+	Feeds       map[string]string  `json:"feeds"` // feed URLs keyed by format
+}
+
+// handleProviders serves GET /api/providers, every provider sorted by id.
+func (s *Server) handleProviders(w http.ResponseWriter, r *http.Request) {
+	defs := s.registry.Definitions()
+	infos := make([]ProviderInfo, 0, len(defs))
+	for _, def := range defs {
+		infos = append(infos, s.providerInfo(def, baseURL(r)))
+	}
+	writeJSON(w, infos)
+}
+
+// handleProvider serves GET /api/providers/{id}.
+func (s *Server) handleProvider(w http.ResponseWriter, r *http.Request) {
+	def, ok := s.registry.Definition(r.PathValue("id"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, s.providerInfo(def, baseURL(r)))
+}
+
+// handleProviderItems serves GET /api/providers/{id}/items, the current
+// feed of the provider, scraped when it is not cached.
+func (s *Server) handleProviderItems(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	scraper, ok := s.registry.Scraper(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	feed, err := scraper.Scrape(r.Context())
+	if err != nil {
+		log.Printf("Error scraping %s: %v", id, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if feed.Items == nil {
+		feed.Items = []feedService.Article{}
+	}
+	writeJSON(w, feed)
+}
+
+// handleProviderRuns serves GET /api/providers/{id}/runs, the last scrapes
+// of the provider, the most recent first.
+func (s *Server) handleProviderRuns(w http.ResponseWriter, r *http.Request) {
+	scraper, ok := s.registry.Scraper(r.PathValue("id"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	runs := []providers.Run{}
+	if reporter, ok := scraper.(providers.RunReporter); ok {
+		runs = append(runs, reporter.Runs()...)
+	}
+	writeJSON(w, runs)
+}
+
+func (s *Server) providerInfo(def *providers.Definition, base string) ProviderInfo {
+	info := ProviderInfo{
+		ID:       def.ID,
+		Name:     def.Title,
+		Link:     def.Link,
+		URL:      def.URL,
+		Schedule: def.Schedule,
+		Feeds:    map[string]string{},
+	}
+	if s.cron != nil {
+		if job, ok := s.cron.Job(def.ID); ok {
+			info.Schedule = job.Schedule
+			info.NextRun = job.NextRun
+		}
+	}
+	if scraper, ok := s.registry.Scraper(def.ID); ok {
+		if reporter, ok := scraper.(providers.StatusReporter); ok {
+			status := reporter.Status()
+			info.LastSuccess = status.LastSuccess
+			info.LastError = status.LastError
+			info.LastErrorAt = status.LastErrorAt
+			info.Failing = status.Failing
+			info.Items = status.Items
+		}
+	}
+	for _, format := range feedService.Formats() {
+		info.Feeds[string(format)] = feedURL(base, def.ID, format)
+	}
+	return info
+}
+
+// writeJSON writes v as an indented JSON response.
+func writeJSON(w http.ResponseWriter, v any) {
+	writeJSONStatus(w, http.StatusOK, v)
+}
+
+// writeJSONStatus writes v as an indented JSON response with the status
+// code.
+func writeJSONStatus(w http.ResponseWriter, status int, v any) {
+	body, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		log.Printf("Error encoding JSON response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(body)
+}
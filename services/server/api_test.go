@@ -0,0 +1,61 @@
+package serverService
+
+import (
+	"encoding/json"
+	"net/http"
+	"rss-generator/providers"
+	feedService "rss-generator/services/feed"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Providers(t *testing.T) {
+	s := testMergeServer(t)
+
+	var infos []ProviderInfo
+	rec := get(s, "/api/providers", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &infos))
+	if assert.Len(t, infos, 2) {
+		assert.Equal(t, "example", infos[0].ID)
+		assert.Equal(t, "Example", infos[0].Name)
+		assert.Equal(t, "https://example.example.com/", infos[0].Link)
+		assert.True(t, infos[0].LastSuccess.IsZero(), "not scraped yet")
+		assert.Equal(t, "http://example.com/feed/example/atom.xml", infos[0].Feeds["atom"])
+	}
+
+	// Reading the items scrapes the provider
+	var feed feedService.Feed
+	rec = get(s, "/api/providers/example/items", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &feed))
+	if assert.Len(t, feed.Items, 2) {
+		assert.Equal(t, "First", feed.Items[0].Title)
+		assert.Equal(t, "https://www.example.com/first", feed.Items[0].Link)
+	}
+
+	var info ProviderInfo
+	rec = get(s, "/api/providers/example", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &info))
+	assert.False(t, info.LastSuccess.IsZero())
+	assert.Equal(t, 2, info.Items)
+
+	assert.Equal(t, http.StatusNotFound, get(s, "/api/providers/unknown", nil).Code)
+	assert.Equal(t, http.StatusNotFound, get(s, "/api/providers/unknown/items", nil).Code)
+	assert.Equal(t, http.StatusNotFound, get(s, "/api/providers/all/items", nil).Code, "bundles are not providers")
+
+	var runs []providers.Run
+	rec = get(s, "/api/providers/example/runs", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &runs))
+	if assert.Len(t, runs, 1) {
+		assert.Equal(t, providers.TriggerCacheMiss, runs[0].Trigger)
+		assert.Equal(t, providers.RunSucceeded, runs[0].Outcome)
+		assert.Equal(t, 2, runs[0].Items)
+	}
+	assert.Equal(t, "[]", get(s, "/api/providers/other/runs", nil).Body.String())
+	assert.Equal(t, http.StatusNotFound, get(s, "/api/providers/unknown/runs", nil).Code)
+}
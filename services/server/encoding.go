@@ -0,0 +1,125 @@
+package serverService
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Content codings supported by Server, in order of preference.
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+// brotliLevel trades a slightly larger body for a compression an order of
+// magnitude faster than the best level, as feeds are compressed on the
+// request path.
+const brotliLevel = 5
+
+// rendition is a rendered feed with its compressed variants, computed once
+// per scrape instead of once per request.
+type rendition struct {
+	source       string // identifies the feed it was rendered from, see renditionSource
+	lastModified time.Time
+	etag         string
+	body         []byte
+
+	mu       sync.Mutex
+	variants map[string][]byte // compressed bodies keyed by content coding, computed on first use
+}
+
+func newRendition(body, source string, lastModified time.Time) *rendition {
+	return &rendition{
+		source:       source,
+		lastModified: lastModified,
+		etag:         etag(body),
+		body:         []byte(body),
+		variants:     make(map[string][]byte),
+	}
+}
+
+// variant returns the body in the given coding with its ETag, compressing
+// it on the first request for the coding. A strong ETag identifies one
+// representation, so each coding gets its own. The identity is returned,
+// with an empty coding, when the compression fails.
+func (r *rendition) variant(encoding string) ([]byte, string, string) {
+	if encoding == "" {
+		return r.body, r.etag, ""
+	}
+	// Concurrent requests for the same coding wait for a single compression
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	compressed, ok := r.variants[encoding]
+	if !ok {
+		var err error
+		compressed, err = compress(encoding, r.body)
+		if err != nil {
+			log.Printf("Error compressing a feed with %s: %v", encoding, err)
+			return r.body, r.etag, ""
+		}
+		r.variants[encoding] = compressed
+	}
+	return compressed, strings.TrimSuffix(r.etag, `"`) + "-" + encoding + `"`, encoding
+}
+
+// compress encodes the body with a content coding.
+func compress(encoding string, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case encodingBrotli:
+		w = brotli.NewWriterLevel(&buf, brotliLevel)
+	default:
+		w = gzip.NewWriter(&buf)
+	}
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// negotiateEncoding picks the content coding of the response from the
+// Accept-Encoding header of the request, "" meaning no compression.
+func negotiateEncoding(header string) string {
+	if header == "" {
+		return ""
+	}
+	weights := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(key, "q") {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		weights[coding] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, coding := range []string{encodingBrotli, encodingGzip} {
+		q, ok := weights[coding]
+		if !ok {
+			q, ok = weights["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
@@ -0,0 +1,125 @@
+package serverService
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"rss-generator/providers"
+	feedService "rss-generator/services/feed"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	errNotFound   = errors.New("not found")
+	errBadRequest = errors.New("bad request")
+)
+
+// writeError answers a request with the status matching err.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, errNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, errBadRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("Error serving %s: %v", r.URL.Path, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// mergeTarget returns the channel and the providers of a bundle, or of the
+// merged feed whose providers are listed in the providers parameter.
+func (s *Server) mergeTarget(name string, r *http.Request) (*feedService.Feed, []string, error) {
+	if name != providers.MergeID {
+		bundle, ok := s.registry.Bundle(name)
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: no provider or bundle %s", errNotFound, name)
+		}
+		meta := &feedService.Feed{
+			Title:       bundle.Title,
+			Link:        baseURL(r) + "/",
+			Description: bundle.Description,
+		}
+		return meta, bundle.Providers, nil
+	}
+
+	var ids, titles []string
+	for _, raw := range r.URL.Query()["providers"] {
+		for _, id := range strings.Split(raw, ",") {
+			id = strings.TrimSpace(id)
+			if id == "" || slices.Contains(ids, id) {
+				continue
+			}
+			def, ok := s.registry.Definition(id)
+			if !ok {
+				return nil, nil, fmt.Errorf("%w: unknown provider %s", errNotFound, id)
+			}
+			ids = append(ids, id)
+			titles = append(titles, def.Title)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil, fmt.Errorf("%w: the providers parameter lists the merged providers", errBadRequest)
+	}
+	meta := &feedService.Feed{
+		Title:       strings.Join(titles, " + "),
+		Link:        baseURL(r) + "/",
+		Description: "Latest articles from " + strings.Join(titles, ", "),
+	}
+	return meta, ids, nil
+}
+
+// merge scrapes the providers concurrently and merges their feeds, each
+// item tagged with its provider. Providers that fail are left out, the
+// merge only fails when all of them do.
+func (s *Server) merge(r *http.Request, format feedService.Format, meta *feedService.Feed, ids []string) (*feedService.Feed, error) {
+	feeds := make([]*feedService.Feed, len(ids))
+	errs := make([]error, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		scraper, ok := s.registry.Scraper(id)
+		def, _ := s.registry.Definition(id)
+		if !ok || def == nil {
+			errs[i] = fmt.Errorf("unknown provider %s", id)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			feed, err := scraper.Scrape(r.Context())
+			if err != nil {
+				errs[i] = err
+				log.Printf("Error scraping %s for a merged feed: %v", id, err)
+				return
+			}
+			source := &feedService.Source{
+				ID:      id,
+				Title:   def.Title,
+				Link:    def.Link,
+				FeedURL: feedURL(baseURL(r), id, format),
+			}
+			// The feed may be shared with concurrent requests
+			tagged := *feed
+			tagged.Items = slices.Clone(feed.Items)
+			for j := range tagged.Items {
+				tagged.Items[j].Source = source
+			}
+			feeds[i] = &tagged
+		}()
+	}
+	wg.Wait()
+
+	var merged []*feedService.Feed
+	for _, feed := range feeds {
+		if feed != nil {
+			merged = append(merged, feed)
+		}
+	}
+	if len(merged) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	return feedService.Merge(meta, merged...), nil
+}
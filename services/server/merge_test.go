@@ -0,0 +1,58 @@
+package serverService
+
+import (
+	"net/http"
+	"rss-generator/providers"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const otherRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel><title>Other</title>
+<item><title>Between</title><link>https://other.example.com/between</link><pubDate>Fri, 27 Oct 2023 22:00:00 GMT</pubDate></item>
+<item><title>Second again</title><link>https://www.example.com/second</link><pubDate>Sat, 28 Oct 2023 09:00:00 GMT</pubDate></item>
+</channel></rss>`
+
+func testMergeServer(t *testing.T) *Server {
+	registry := testRegistry(t)
+	registerUpstream(t, registry, "other", otherRSS)
+	assert.NoError(t, registry.RegisterBundle(&providers.Bundle{ID: "all", Title: "Everything", Providers: []string{"example", "other"}}))
+	return NewServer(registry, nil)
+}
+
+func TestServer_Merge(t *testing.T) {
+	s := testMergeServer(t)
+
+	rec := get(s, "/feed/merge/rss.xml?providers=example,other", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "<title>Example + Other</title>")
+	second := strings.Index(body, "<title>Second</title>")
+	between := strings.Index(body, "<title>Between</title>")
+	first := strings.Index(body, "<title>First</title>")
+	assert.True(t, second < between && between < first, "items are sorted by date")
+	assert.NotContains(t, body, "Second again", "items are deduplicated by link")
+	assert.Contains(t, body, `<source url="http://example.com/feed/other/rss.xml">Other</source>`)
+	assert.Equal(t, "public, max-age=3600", rec.Header().Get("Cache-Control"))
+
+	// Filters apply to the merged items
+	rec = get(s, "/feed/merge/atom.xml?providers=other,example&limit=1", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, strings.Count(rec.Body.String(), "<entry>"))
+
+	assert.Equal(t, http.StatusBadRequest, get(s, "/feed/merge/rss.xml", nil).Code)
+	assert.Equal(t, http.StatusNotFound, get(s, "/feed/merge/rss.xml?providers=example,unknown", nil).Code)
+}
+
+func TestServer_Bundle(t *testing.T) {
+	s := testMergeServer(t)
+
+	rec := get(s, "/feed/all/feed.json", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, `"title": "Everything"`)
+	assert.Contains(t, body, `"_source"`)
+	assert.Contains(t, body, `"feed_url": "http://example.com/feed/example/feed.json"`)
+}
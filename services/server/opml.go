@@ -0,0 +1,91 @@
+package serverService
+
+import (
+	"encoding/xml"
+	"net/http"
+	"rss-generator/providers"
+	feedService "rss-generator/services/feed"
+	"time"
+)
+
+// OPML is an OPML 2.0 subscription list.
+type OPML struct {
+	XMLName xml.Name    `xml:"opml"`
+	Version string      `xml:"version,attr"`
+	Head    OPMLHead    `xml:"head"`
+	Body    []OPMLEntry `xml:"body>outline"`
+}
+
+type OPMLHead struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated"`
+}
+
+// OPMLEntry is the outline of a feed. Readers subscribe to XMLURL, the URLs
+// of the other formats are listed in Formats as rssUrl, atomUrl and jsonUrl.
+type OPMLEntry struct {
+	Type        string     `xml:"type,attr"`
+	Text        string     `xml:"text,attr"`
+	Title       string     `xml:"title,attr"`
+	Description string     `xml:"description,attr,omitempty"`
+	HTMLURL     string     `xml:"htmlUrl,attr"`
+	XMLURL      string     `xml:"xmlUrl,attr"`
+	Formats     []xml.Attr `xml:",any,attr"`
+}
+
+// handleOPML serves /opml, the catalog of every provider to import in a
+// reader. The format parameter picks the format subscribed to, RSS by
+// default.
+func (s *Server) handleOPML(w http.ResponseWriter, r *http.Request) {
+	format := feedService.FormatRSS
+	if raw := r.URL.Query().Get("format"); raw != "" {
+		format = feedService.Format(raw)
+		if feedService.FileName(format) == "" {
+			http.Error(w, "unsupported format "+raw, http.StatusBadRequest)
+			return
+		}
+	}
+
+	body, err := xml.MarshalIndent(buildOPML(s.registry.Definitions(), baseURL(r), format), "", "  ")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/x-opml; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="feeds.opml"`)
+	w.Write([]byte(xml.Header))
+	w.Write(body)
+}
+
+func buildOPML(defs []*providers.Definition, base string, format feedService.Format) *OPML {
+	opml := &OPML{
+		Version: "2.0",
+		Head: OPMLHead{
+			Title:       "RSS Feed generator",
+			DateCreated: time.Now().UTC().Format(time.RFC1123Z),
+		},
+	}
+	for _, def := range defs {
+		entry := OPMLEntry{
+			Type:        "rss",
+			Text:        def.Title,
+			Title:       def.Title,
+			Description: def.Description,
+			HTMLURL:     def.Link,
+			XMLURL:      feedURL(base, def.ID, format),
+		}
+		for _, f := range feedService.Formats() {
+			entry.Formats = append(entry.Formats, xml.Attr{
+				Name:  xml.Name{Local: string(f) + "Url"},
+				Value: feedURL(base, def.ID, f),
+			})
+		}
+		opml.Body = append(opml.Body, entry)
+	}
+	return opml
+}
+
+// feedURL returns the public URL of a feed.
+func feedURL(base, id string, format feedService.Format) string {
+	return base + "/feed/" + id + "/" + feedService.FileName(format)
+}
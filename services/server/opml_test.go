@@ -0,0 +1,39 @@
+package serverService
+
+import (
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_OPML(t *testing.T) {
+	s := testMergeServer(t)
+
+	rec := get(s, "/opml", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/x-opml; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	var opml OPML
+	assert.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &opml))
+	assert.Equal(t, "2.0", opml.Version)
+	if assert.Len(t, opml.Body, 2, "one outline per provider, bundles are left out") {
+		entry := opml.Body[0]
+		assert.Equal(t, "rss", entry.Type)
+		assert.Equal(t, "Example", entry.Text)
+		assert.Equal(t, "https://example.example.com/", entry.HTMLURL)
+		assert.Equal(t, "http://example.com/feed/example/rss.xml", entry.XMLURL)
+		assert.Equal(t, "Other", opml.Body[1].Text)
+	}
+	body := rec.Body.String()
+	assert.Contains(t, body, `atomUrl="http://example.com/feed/example/atom.xml"`)
+	assert.Contains(t, body, `jsonUrl="http://example.com/feed/other/feed.json"`)
+
+	rec = get(s, "/opml?format=atom", map[string]string{"X-Forwarded-Proto": "https"})
+	opml = OPML{}
+	assert.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &opml))
+	assert.Equal(t, "https://example.com/feed/example/atom.xml", opml.Body[0].XMLURL)
+
+	assert.Equal(t, http.StatusBadRequest, get(s, "/opml?format=txt", nil).Code)
+}
@@ -0,0 +1,307 @@
+package serverService
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"rss-generator/providers"
+	cronService "rss-generator/services/cron"
+	feedService "rss-generator/services/feed"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server serves the feeds of the providers of a registry.
+type Server struct {
+	registry *providers.Registry
+	cron     *cronService.CronService // gives the next refresh of the feeds, may be nil
+	mux      *http.ServeMux
+
+	// AdminToken is the bearer token of the admin endpoints, they are
+	// disabled when it is empty.
+	AdminToken string
+	// StaleNotice adds a notice to the description of the feeds that could
+	// not be refreshed, for the readers that ignore the X-Feed-* headers.
+	StaleNotice bool
+
+	mu         sync.Mutex
+	renditions map[string]*rendition  // last rendition of every unfiltered feed and format
+	rendering  map[string]*renderCall // renders in progress, shared by concurrent requests
+}
+
+// renderCall is a render in progress.
+type renderCall struct {
+	done     chan struct{}
+	source   string
+	rendered *rendition
+	err      error
+}
+
+// maxRenditions bounds the renditions kept, as every merge of providers has
+// its own.
+const maxRenditions = 256
+
+func NewServer(registry *providers.Registry, cron *cronService.CronService) *Server {
+	s := &Server{
+		registry:   registry,
+		cron:       cron,
+		mux:        http.NewServeMux(),
+		renditions: make(map[string]*rendition),
+		rendering:  make(map[string]*renderCall),
+	}
+	s.mux.HandleFunc("/feed/", s.handleFeed)
+	s.mux.HandleFunc("/opml", s.handleOPML)
+	s.mux.HandleFunc("GET /api/providers", s.handleProviders)
+	s.mux.HandleFunc("GET /api/providers/{id}", s.handleProvider)
+	s.mux.HandleFunc("GET /api/providers/{id}/items", s.handleProviderItems)
+	s.mux.HandleFunc("GET /api/providers/{id}/runs", s.handleProviderRuns)
+	s.mux.HandleFunc("POST /admin/providers/{id}/refresh", s.admin(s.handleRefresh))
+	s.mux.HandleFunc("/metrics", s.handleMetrics)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleFeed serves /feed/{provider}/{file}, e.g. /feed/theverge/rss.xml,
+// as well as the bundles and the ad hoc merged feeds, see merge.go.
+func (s *Server) handleFeed(w http.ResponseWriter, r *http.Request) {
+	// Extract the provider name and the format from the URL path
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 4 {
+		http.NotFound(w, r)
+		return
+	}
+	format, ok := feedService.FormatFromFileName(parts[len(parts)-1])
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	providerName := parts[len(parts)-2]
+
+	filter, err := feedService.ParseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var feed *feedService.Feed
+	var ids []string // providers the feed is made of
+	key := providerName + "/" + string(format) + "?" + feedService.FilterQuery(r.URL.Query())
+	if scraper, ok := s.registry.Scraper(providerName); ok {
+		ids = []string{providerName}
+		feed, err = scraper.Scrape(r.Context())
+	} else {
+		// Check if the name is a bundle or the merged feed
+		var meta *feedService.Feed
+		meta, ids, err = s.mergeTarget(providerName, r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if providerName == providers.MergeID {
+			key += "&providers=" + strings.Join(ids, ",")
+		}
+		feed, err = s.merge(r, format, meta, ids)
+	}
+	if err != nil {
+		log.Printf("Error scraping %s: %v", providerName, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	failures := s.failures(ids)
+	if len(failures) > 0 {
+		setStaleHeaders(w, feed, failures)
+		if s.StaleNotice {
+			feed = staleNotice(feed, failures)
+		}
+	}
+	if !filter.IsZero() {
+		feed = filter.Apply(feed)
+	}
+	rendered, err := s.render(key, filter.IsZero(), format, feed, requestURL(r))
+	if err != nil {
+		log.Printf("Error building %s %s feed: %v", providerName, format, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", feedService.ContentType(format))
+	if maxAge, ok := s.maxAge(ids...); ok {
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	}
+	serveFeed(w, r, rendered)
+}
+
+// render returns the rendition of the feed, reusing the previous one of key
+// while the feed has not changed. Concurrent requests for the same feed share
+// a single render. Only the renditions of unfiltered feeds are kept, as any
+// query would otherwise add one.
+func (s *Server) render(key string, keep bool, format feedService.Format, feed *feedService.Feed, selfURL string) (*rendition, error) {
+	source := renditionSource(feed, selfURL)
+	s.mu.Lock()
+	if cached, ok := s.renditions[key]; ok && cached.source == source {
+		s.mu.Unlock()
+		return cached, nil
+	}
+	if call, ok := s.rendering[key]; ok && call.source == source {
+		s.mu.Unlock()
+		<-call.done
+		return call.rendered, call.err
+	}
+	call := &renderCall{done: make(chan struct{}), source: source}
+	s.rendering[key] = call
+	s.mu.Unlock()
+
+	body, err := feedService.Build(format, feed, feedService.Options{SelfURL: selfURL})
+	if err == nil {
+		call.rendered = newRendition(body, source, feed.LastModified())
+	}
+	call.err = err
+
+	s.mu.Lock()
+	if s.rendering[key] == call {
+		delete(s.rendering, key)
+	}
+	if err == nil && keep {
+		if _, ok := s.renditions[key]; !ok && len(s.renditions) >= maxRenditions {
+			for evicted := range s.renditions {
+				delete(s.renditions, evicted)
+				break
+			}
+		}
+		s.renditions[key] = call.rendered
+	}
+	s.mu.Unlock()
+	close(call.done)
+	return call.rendered, call.err
+}
+
+// renditionSource identifies a feed: a new scrape changes its Updated date,
+// a failed one its description when StaleNotice is set, and a filter
+// relative to the current time, such as since=24h, its items.
+func renditionSource(feed *feedService.Feed, selfURL string) string {
+	var b strings.Builder
+	b.WriteString(selfURL)
+	b.WriteString("\n")
+	b.WriteString(feed.Updated.Format(time.RFC3339Nano))
+	b.WriteString("\n")
+	b.WriteString(feed.Description)
+	for _, item := range feed.Items {
+		b.WriteString("\n")
+		b.WriteString(item.GUID)
+	}
+	return b.String()
+}
+
+// maxAge returns how long clients may cache a feed made of the given
+// providers: until the first of their next scheduled refreshes.
+func (s *Server) maxAge(ids ...string) (time.Duration, bool) {
+	var maxAge time.Duration
+	found := false
+	for _, id := range ids {
+		age, ok := s.providerMaxAge(id)
+		if ok && (!found || age < maxAge) {
+			maxAge, found = age, true
+		}
+	}
+	return maxAge, found
+}
+
+func (s *Server) providerMaxAge(id string) (time.Duration, bool) {
+	if s.cron != nil {
+		if job, ok := s.cron.Job(id); ok && !job.NextRun.IsZero() {
+			return max(time.Until(job.NextRun), 0), true
+		}
+	}
+	if def, ok := s.registry.Definition(id); ok && def.TTL > 0 {
+		return time.Duration(def.TTL), true
+	}
+	return 0, false
+}
+
+// serveFeed writes a rendered feed, compressed when the client accepts it,
+// with its validators. http.ServeContent answers the conditional requests,
+// If-None-Match / If-Modified-Since, with 304 Not Modified.
+func serveFeed(w http.ResponseWriter, r *http.Request, rendered *rendition) {
+	body, tag, encoding := rendered.variant(negotiateEncoding(r.Header.Get("Accept-Encoding")))
+	w.Header().Add("Vary", "Accept-Encoding")
+	if encoding != "" {
+		w.Header().Set("Content-Encoding", encoding)
+	}
+	w.Header().Set("ETag", tag)
+	http.ServeContent(w, r, "", rendered.lastModified, bytes.NewReader(body))
+}
+
+// etag returns a strong ETag derived from the content of the body, so it
+// only changes when the feed does.
+func etag(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeMetrics(w, s.registry)
+}
+
+// requestURL rebuilds the public URL of the request.
+func requestURL(r *http.Request) string {
+	return baseURL(r) + r.URL.Path
+}
+
+// baseURL returns the public scheme and host of the server, honouring the
+// headers set by a reverse proxy.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	host := r.Host
+	if forwarded := r.Header.Get("X-Forwarded-Host"); forwarded != "" {
+		host = forwarded
+	}
+	return scheme + "://" + host
+}
+
+// writeMetrics writes the scrape metrics of every provider in the Prometheus
+// text format.
+func writeMetrics(w io.Writer, registry *providers.Registry) {
+	counters := []struct {
+		name, help string
+		value      func(providers.Metrics) uint64
+	}{
+		{"rss_generator_cache_hits_total", "Requests served from a fresh cache entry.", func(m providers.Metrics) uint64 { return m.CacheHits }},
+		{"rss_generator_stale_hits_total", "Requests served from an expired cache entry while refreshing.", func(m providers.Metrics) uint64 { return m.StaleHits }},
+		{"rss_generator_cache_misses_total", "Requests that had to wait for a scrape.", func(m providers.Metrics) uint64 { return m.Misses }},
+		{"rss_generator_coalesced_total", "Requests that joined a scrape already in flight.", func(m providers.Metrics) uint64 { return m.Coalesced }},
+		{"rss_generator_scrapes_total", "Scrapes started.", func(m providers.Metrics) uint64 { return m.Scrapes }},
+		{"rss_generator_scrape_errors_total", "Scrapes that failed.", func(m providers.Metrics) uint64 { return m.Errors }},
+	}
+	metrics := map[string]providers.Metrics{}
+	defs := registry.Definitions()
+	for _, def := range defs {
+		if scraper, ok := registry.Scraper(def.ID); ok {
+			if reporter, ok := scraper.(providers.MetricsReporter); ok {
+				metrics[def.ID] = reporter.Metrics()
+			}
+		}
+	}
+	for _, counter := range counters {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", counter.name, counter.help, counter.name)
+		for _, def := range defs {
+			if m, ok := metrics[def.ID]; ok {
+				fmt.Fprintf(w, "%s{provider=%q} %d\n", counter.name, def.ID, counter.value(m))
+			}
+		}
+	}
+}
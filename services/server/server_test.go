@@ -0,0 +1,291 @@
+package serverService
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"rss-generator/providers"
+	cacheService "rss-generator/services/cache"
+	cronService "rss-generator/services/cron"
+	feedService "rss-generator/services/feed"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/assert"
+)
+
+const upstreamRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel><title>Upstream</title>
+<item><title>First</title><link>https://www.example.com/first</link><pubDate>Fri, 27 Oct 2023 10:00:00 GMT</pubDate></item>
+<item><title>Second</title><link>https://www.example.com/second</link><pubDate>Sat, 28 Oct 2023 10:00:00 GMT</pubDate></item>
+</channel></rss>`
+
+// testServer serves a provider passing through an upstream feed.
+func testServer(t *testing.T) *Server {
+	return NewServer(testRegistry(t), nil)
+}
+
+func testRegistry(t *testing.T) *providers.Registry {
+	registry := providers.NewRegistry(cacheService.NewMemoryCache(), nil)
+	registerUpstream(t, registry, "example", upstreamRSS)
+	return registry
+}
+
+// registerUpstream registers a provider passing through the feed body.
+func registerUpstream(t *testing.T, registry *providers.Registry, id, body string) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(upstream.Close)
+
+	err := registry.Register(&providers.Definition{
+		ID:    id,
+		Title: strings.ToUpper(id[:1]) + id[1:],
+		Link:  "https://" + id + ".example.com/",
+		URL:   upstream.URL,
+		Fetch: providers.FetchFeed,
+		TTL:   providers.Duration(time.Hour),
+	})
+	assert.NoError(t, err)
+}
+
+func get(s *Server, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_Feed(t *testing.T) {
+	s := testServer(t)
+
+	rec := get(s, "/feed/example/rss.xml", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/rss+xml; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "<title>Second</title>")
+
+	assert.Equal(t, http.StatusNotFound, get(s, "/feed/unknown/rss.xml", nil).Code)
+	assert.Equal(t, http.StatusNotFound, get(s, "/feed/example/feed.txt", nil).Code)
+	assert.Equal(t, http.StatusNotFound, get(s, "/feed/", nil).Code)
+}
+
+func TestServer_Feed_ConditionalGet(t *testing.T) {
+	s := testServer(t)
+
+	rec := get(s, "/feed/example/atom.xml", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	tag := rec.Header().Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, tag)
+	// The items are older than the scrape that found them
+	lastModified := rec.Header().Get("Last-Modified")
+	date, err := http.ParseTime(lastModified)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), date, time.Minute)
+
+	// The ETag only depends on the content
+	assert.Equal(t, tag, get(s, "/feed/example/atom.xml", nil).Header().Get("ETag"))
+	assert.NotEqual(t, tag, get(s, "/feed/example/rss.xml", nil).Header().Get("ETag"))
+
+	rec = get(s, "/feed/example/atom.xml", map[string]string{"If-None-Match": tag})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, tag, rec.Header().Get("ETag"))
+
+	rec = get(s, "/feed/example/atom.xml", map[string]string{"If-None-Match": `"other", ` + tag})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	rec = get(s, "/feed/example/atom.xml", map[string]string{"If-None-Match": `"other"`})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = get(s, "/feed/example/atom.xml", map[string]string{"If-Modified-Since": lastModified})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	rec = get(s, "/feed/example/atom.xml", map[string]string{"If-Modified-Since": "Sat, 28 Oct 2023 10:00:00 GMT"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// If-None-Match takes precedence over If-Modified-Since
+	rec = get(s, "/feed/example/atom.xml", map[string]string{
+		"If-None-Match":     `"other"`,
+		"If-Modified-Since": time.Now().UTC().Format(http.TimeFormat),
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestServer_Metrics(t *testing.T) {
+	s := testServer(t)
+	get(s, "/feed/example/rss.xml", nil)
+	get(s, "/feed/example/rss.xml", nil)
+
+	rec := get(s, "/metrics", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.True(t, strings.Contains(body, `rss_generator_cache_hits_total{provider="example"} 1`), body)
+	assert.Contains(t, body, `rss_generator_scrapes_total{provider="example"} 1`)
+}
+
+func TestServer_Feed_Compression(t *testing.T) {
+	s := testServer(t)
+	plain := get(s, "/feed/example/rss.xml", nil)
+	assert.Empty(t, plain.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", plain.Header().Get("Vary"))
+
+	rec := get(s, "/feed/example/rss.xml", map[string]string{"Accept-Encoding": "gzip, deflate"})
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	reader, err := gzip.NewReader(rec.Body)
+	if !assert.NoError(t, err) {
+		return
+	}
+	body, _ := io.ReadAll(reader)
+	assert.Equal(t, plain.Body.String(), string(body))
+
+	rec = get(s, "/feed/example/rss.xml", map[string]string{"Accept-Encoding": "gzip, br"})
+	assert.Equal(t, "br", rec.Header().Get("Content-Encoding"))
+	body, _ = io.ReadAll(brotli.NewReader(rec.Body))
+	assert.Equal(t, plain.Body.String(), string(body))
+
+	// Each representation has its own strong ETag
+	tag := rec.Header().Get("ETag")
+	assert.NotEqual(t, plain.Header().Get("ETag"), tag)
+	rec = get(s, "/feed/example/rss.xml", map[string]string{"Accept-Encoding": "br", "If-None-Match": tag})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	// The variants are computed once per scrape
+	s.mu.Lock()
+	first := s.renditions["example/rss?"]
+	s.mu.Unlock()
+	get(s, "/feed/example/rss.xml", map[string]string{"Accept-Encoding": "gzip"})
+	s.mu.Lock()
+	assert.Same(t, first, s.renditions["example/rss?"])
+	s.mu.Unlock()
+
+	// and only for the codings requested
+	get(s, "/feed/example/atom.xml", map[string]string{"Accept-Encoding": "gzip"})
+	s.mu.Lock()
+	atom := s.renditions["example/atom?"]
+	s.mu.Unlock()
+	atom.mu.Lock()
+	assert.Len(t, atom.variants, 1)
+	assert.Contains(t, atom.variants, encodingGzip)
+	atom.mu.Unlock()
+
+	// Filtered feeds are not kept
+	get(s, "/feed/example/rss.xml?limit=1", map[string]string{"Accept-Encoding": "br"})
+	s.mu.Lock()
+	assert.Len(t, s.renditions, 2)
+	s.mu.Unlock()
+}
+
+func TestServer_Render_Coalesced(t *testing.T) {
+	s := testServer(t)
+	feed, err := s.registry.MustScraper("example").Scrape(context.Background())
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	renditions := make([]*rendition, 8)
+	for i := range renditions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			renditions[i], _ = s.render("example/rss?limit=1", false, feedService.FormatRSS, feed, "http://example.com/feed/example/rss.xml")
+		}()
+	}
+	wg.Wait()
+	for _, rendered := range renditions[1:] {
+		assert.Equal(t, renditions[0].etag, rendered.etag)
+	}
+	assert.Empty(t, s.renditions)
+	assert.Empty(t, s.rendering)
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := map[string]string{
+		"":                     "",
+		"identity":             "",
+		"gzip":                 "gzip",
+		"deflate, gzip":        "gzip",
+		"gzip, br":             "br",
+		"br;q=0.5, gzip":       "gzip",
+		"br;q=0, gzip;q=0":     "",
+		"*":                    "br",
+		"*;q=0.5, gzip;q=0.8":  "gzip",
+		"GZIP;Q=0.9, br;q=0.1": "gzip",
+	}
+	for header, expected := range tests {
+		assert.Equal(t, expected, negotiateEncoding(header), header)
+	}
+}
+
+func TestServer_Feed_CacheControl(t *testing.T) {
+	rec := get(testServer(t), "/feed/example/rss.xml", nil)
+	assert.Equal(t, "public, max-age=3600", rec.Header().Get("Cache-Control"), "without cron the TTL is used")
+
+	registry := testRegistry(t)
+	def, _ := registry.Definition("example")
+	def.Schedule.Cron = "@every 10m"
+	cron := cronService.NewCronService(providers.Schedule{})
+	assert.NoError(t, cron.AddProviders(registry))
+	rec = get(NewServer(registry, cron), "/feed/example/rss.xml", nil)
+	assert.Regexp(t, `^public, max-age=(599|600)$`, rec.Header().Get("Cache-Control"))
+}
+
+func TestServer_Feed_Filter(t *testing.T) {
+	s := testServer(t)
+
+	rec := get(s, "/feed/example/rss.xml?include=second", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "<title>Second</title>")
+	assert.NotContains(t, rec.Body.String(), "<title>First</title>")
+
+	rec = get(s, "/feed/example/feed.json?limit=1&since=2023-10-28", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Second")
+	assert.NotContains(t, rec.Body.String(), "First")
+
+	// Filtered and unfiltered feeds do not share their rendition
+	assert.NotEqual(t, get(s, "/feed/example/rss.xml", nil).Header().Get("ETag"), get(s, "/feed/example/rss.xml?limit=1", nil).Header().Get("ETag"))
+
+	rec = get(s, "/feed/example/rss.xml?limit=many", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestServer_Feed_ConditionalGet_Rescraped(t *testing.T) {
+	cache := cacheService.NewMemoryCache()
+	registry := providers.NewRegistry(cache, nil)
+	registerUpstream(t, registry, "example", upstreamRSS)
+	s := NewServer(registry, nil)
+	scraper := registry.MustScraper("example")
+	feed, err := scraper.Scrape(context.Background(), "true")
+	assert.NoError(t, err)
+
+	// Date the first scrape an hour back, as if it ran at the previous cron
+	feed.Updated = feed.Updated.Add(-time.Hour)
+	feed.Changed = feed.Updated
+	content, _ := json.Marshal(feed)
+	cache.Set("rss-example", string(content), time.Hour)
+
+	paths := []string{"/feed/example/rss.xml", "/feed/example/atom.xml", "/feed/example/feed.json"}
+	first := map[string]*httptest.ResponseRecorder{}
+	for _, path := range paths {
+		first[path] = get(s, path, nil)
+	}
+
+	// The same items scraped again are not modified
+	_, err = scraper.Scrape(context.Background(), "true")
+	assert.NoError(t, err)
+	for _, path := range paths {
+		rec := get(s, path, map[string]string{
+			"If-None-Match":     first[path].Header().Get("ETag"),
+			"If-Modified-Since": first[path].Header().Get("Last-Modified"),
+		})
+		assert.Equal(t, http.StatusNotModified, rec.Code, path)
+	}
+}
@@ -0,0 +1,75 @@
+package serverService
+
+import (
+	"fmt"
+	"net/http"
+	"rss-generator/providers"
+	feedService "rss-generator/services/feed"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers set on the feeds served while the scrapes of their providers fail.
+const (
+	headerFeedAge   = "X-Feed-Age"   // seconds since the feed was scraped
+	headerFeedError = "X-Feed-Error" // why it could not be refreshed
+)
+
+// maxErrorHeader bounds the length of the X-Feed-Error header.
+const maxErrorHeader = 256
+
+// failures returns the errors of the providers whose last scrape failed,
+// prefixed by their id for merged feeds.
+func (s *Server) failures(ids []string) []string {
+	var failures []string
+	for _, id := range ids {
+		scraper, ok := s.registry.Scraper(id)
+		if !ok {
+			continue
+		}
+		reporter, ok := scraper.(providers.StatusReporter)
+		if !ok {
+			continue
+		}
+		if err, failing := reporter.Failing(); failing {
+			if len(ids) > 1 {
+				err = id + ": " + err
+			}
+			failures = append(failures, err)
+		}
+	}
+	return failures
+}
+
+// setStaleHeaders tells the clients how old a feed that could not be
+// refreshed is, and why.
+func setStaleHeaders(w http.ResponseWriter, feed *feedService.Feed, failures []string) {
+	age := max(time.Since(feed.Updated), 0)
+	w.Header().Set(headerFeedAge, strconv.Itoa(int(age.Seconds())))
+	w.Header().Set(headerFeedError, headerValue(strings.Join(failures, "; ")))
+}
+
+// staleNotice returns a copy of the feed whose description tells the
+// readers it could not be refreshed.
+func staleNotice(feed *feedService.Feed, failures []string) *feedService.Feed {
+	noticed := *feed
+	notice := fmt.Sprintf("Not updated since %s, the last refresh failed: %s",
+		feed.Updated.UTC().Format(time.RFC1123), strings.Join(failures, "; "))
+	if noticed.Description != "" {
+		notice = noticed.Description + " (" + notice + ")"
+	}
+	noticed.Description = notice
+	return &noticed
+}
+
+// headerValue makes an error message fit in a header.
+func headerValue(value string) string {
+	value = strings.Join(strings.FieldsFunc(value, func(r rune) bool {
+		return r == '\r' || r == '\n'
+	}), " ")
+	if len(value) > maxErrorHeader {
+		value = strings.ToValidUTF8(value[:maxErrorHeader], "") + "..."
+	}
+	return value
+}
@@ -0,0 +1,76 @@
+package serverService
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"rss-generator/providers"
+	cacheService "rss-generator/services/cache"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Stale(t *testing.T) {
+	var down atomic.Bool
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			http.Error(w, "maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(upstreamRSS))
+	}))
+	defer upstream.Close()
+	registry := providers.NewRegistry(cacheService.NewMemoryCache(), nil)
+	assert.NoError(t, registry.Register(&providers.Definition{
+		ID:          "example",
+		Description: "Latest articles from Example",
+		URL:         upstream.URL,
+		Fetch:       providers.FetchFeed,
+	}))
+	s := NewServer(registry, nil)
+	refresh := func() error {
+		_, err := registry.MustScraper("example").Scrape(context.Background(), "true")
+		return err
+	}
+
+	rec := get(s, "/feed/example/rss.xml", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("X-Feed-Age"))
+
+	// The last good feed is served with its age and the error
+	down.Store(true)
+	assert.Error(t, refresh())
+	rec = get(s, "/feed/example/rss.xml", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "<title>Second</title>")
+	age, err := strconv.Atoi(rec.Header().Get("X-Feed-Age"))
+	assert.NoError(t, err)
+	assert.Less(t, age, 60)
+	assert.Equal(t, "unexpected status 503 Service Unavailable", rec.Header().Get("X-Feed-Error"))
+	assert.NotContains(t, rec.Body.String(), "refresh failed")
+
+	rec = get(s, "/feed/merge/rss.xml?providers=example", nil)
+	assert.Equal(t, "unexpected status 503 Service Unavailable", rec.Header().Get("X-Feed-Error"))
+
+	s.StaleNotice = true
+	rec = get(s, "/feed/example/feed.json", nil)
+	assert.Contains(t, rec.Body.String(), `"description": "Latest articles from Example (Not updated since `)
+	assert.Contains(t, rec.Body.String(), `the last refresh failed: unexpected status 503 Service Unavailable)"`)
+
+	down.Store(false)
+	assert.NoError(t, refresh())
+	rec = get(s, "/feed/example/feed.json", nil)
+	assert.Empty(t, rec.Header().Get("X-Feed-Error"))
+	assert.Contains(t, rec.Body.String(), `"description": "Latest articles from Example"`)
+}
+
+func TestHeaderValue(t *testing.T) {
+	assert.Equal(t, "line one line two", headerValue("line one\r\nline two"))
+	long := headerValue(strings.Repeat("é", maxErrorHeader))
+	assert.LessOrEqual(t, len(long), maxErrorHeader+3)
+	assert.True(t, strings.HasSuffix(long, "..."))
+}